@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
+	"gorm.io/gorm"
+)
+
+// BroadcastFilter selects the cohort a broadcast goes to. Empty fields match everyone.
+type BroadcastFilter struct {
+	Course string `json:"course"`
+	Year   string `json:"year"`
+	Role   string `json:"role"`
+}
+
+// validate requires at least one field so a typo cannot text the whole campus
+func (f BroadcastFilter) validate() string {
+	if f.Course == "" && f.Year == "" && f.Role == "" {
+		return "Invalid filter: set at least one of course, year or role"
+	}
+	if f.Role != "" && !models.ValidRole(f.Role) {
+		return "Invalid role: " + f.Role
+	}
+	return ""
+}
+
+func (f BroadcastFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Course != "" {
+		query = query.Where("course = ?", f.Course)
+	}
+	if f.Year != "" {
+		query = query.Where("year = ?", f.Year)
+	}
+	if f.Role != "" {
+		query = query.Where("role = ?", f.Role)
+	}
+	return query
+}
+
+// PreviewBroadcast counts who a filter would reach. Only verified numbers are texted.
+func PreviewBroadcast(c *gin.Context) {
+	var filter BroadcastFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	if msg := filter.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, msg)})
+		return
+	}
+
+	var matched, verified int64
+	if err := filter.apply(initializers.DB.Model(&models.User{})).Count(&matched).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to count recipients")})
+		return
+	}
+	filter.apply(initializers.DB.Model(&models.User{})).Where("phone_verified_at IS NOT NULL").Count(&verified)
+
+	c.JSON(http.StatusOK, gin.H{
+		"recipientCount":  verified,
+		"unverifiedCount": matched - verified,
+	})
+}
+
+// CreateBroadcast records a broadcast and its recipients, then sends it in the background
+func CreateBroadcast(c *gin.Context) {
+	type CreateBroadcastRequest struct {
+		Message string `json:"message" binding:"required"`
+		BroadcastFilter
+	}
+
+	var req CreateBroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Message cannot be empty")})
+		return
+	}
+	if msg := req.BroadcastFilter.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, msg)})
+		return
+	}
+
+	var users []models.User
+	if err := req.BroadcastFilter.apply(initializers.DB.Select("id", "phone")).
+		Where("phone_verified_at IS NOT NULL").
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to load recipients")})
+		return
+	}
+	if len(users) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "No verified recipients match the filter")})
+		return
+	}
+
+	broadcast := models.Broadcast{
+		SenderID:       c.GetUint("userID"),
+		Message:        req.Message,
+		Course:         req.Course,
+		Year:           req.Year,
+		Role:           req.Role,
+		Status:         models.BroadcastStatusSending,
+		RecipientCount: len(users),
+	}
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&broadcast).Error; err != nil {
+			return err
+		}
+		recipients := make([]models.BroadcastRecipient, len(users))
+		for i, user := range users {
+			recipients[i] = models.BroadcastRecipient{
+				BroadcastID: broadcast.ID,
+				UserID:      user.ID,
+				Phone:       user.Phone,
+				Status:      models.DeliveryStatusPending,
+			}
+		}
+		return tx.CreateInBatches(&recipients, 500).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to create broadcast: ") + err.Error()})
+		return
+	}
+
+	notifications.SendBroadcastAsync(broadcast.ID)
+	c.JSON(http.StatusAccepted, broadcast)
+}
+
+func GetBroadcasts(c *gin.Context) {
+	_, limit, offset, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	var broadcasts []models.Broadcast
+	if err := initializers.DB.Order("created_at DESC").Limit(limit).Offset(offset).Find(&broadcasts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch broadcasts")})
+		return
+	}
+	c.JSON(http.StatusOK, broadcasts)
+}
+
+// GetBroadcastByID returns a broadcast with its per-recipient delivery status. Use ?status= to filter recipients.
+func GetBroadcastByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid broadcast ID")})
+		return
+	}
+
+	var broadcast models.Broadcast
+	if err := initializers.DB.First(&broadcast, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Broadcast not found")})
+		return
+	}
+
+	query := initializers.DB.Where("broadcast_id = ?", broadcast.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var recipients []models.BroadcastRecipient
+	if err := query.Order("id").Find(&recipients).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch recipients")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"broadcast":  broadcast,
+		"recipients": recipients,
+	})
+}
@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
+	"gorm.io/gorm"
+)
+
+const maxCommentLength = 2000
+
+type CommentResponse struct {
+	ID        uint              `json:"id"`
+	PostID    uint              `json:"postID"`
+	ParentID  *uint             `json:"parentID"`
+	Body      string            `json:"body"`
+	User      UserResponse      `json:"user"`
+	Replies   []CommentResponse `json:"replies,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	HiddenAt  *time.Time        `json:"hiddenAt,omitempty"`
+}
+
+type commentRequest struct {
+	Body     string `json:"body"`
+	ParentID *uint  `json:"parentID"`
+}
+
+func toCommentResponse(comment models.Comment) CommentResponse {
+	return CommentResponse{
+		ID:        comment.ID,
+		PostID:    comment.PostID,
+		ParentID:  comment.ParentID,
+		Body:      comment.Body,
+		User:      toUserResponse(comment.User),
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+		HiddenAt:  comment.HiddenAt,
+	}
+}
+
+// commentCounts counts the visible comments, replies included, on each post
+func commentCounts(postIDs []uint) map[uint]int64 {
+	counts := map[uint]int64{}
+	if len(postIDs) == 0 {
+		return counts
+	}
+
+	var rows []struct {
+		PostID uint
+		Count  int64
+	}
+	initializers.DB.Model(&models.Comment{}).
+		Select("post_id, COUNT(*) AS count").
+		Where("post_id IN ? AND hidden_at IS NULL", postIDs).
+		Group("post_id").
+		Scan(&rows)
+	for _, row := range rows {
+		counts[row.PostID] = row.Count
+	}
+	return counts
+}
+
+// validateCommentBody trims the body and writes a 400 if it is empty or too long
+func validateCommentBody(c *gin.Context, body string) (string, bool) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Comment cannot be empty")})
+		return "", false
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Comment is too long: max ") + strconv.Itoa(maxCommentLength)})
+		return "", false
+	}
+	return body, true
+}
+
+// findPostByParam loads the post in the :id parameter, writing a 400 or 404 on failure
+func findPostByParam(c *gin.Context) (models.Post, bool) {
+	var post models.Post
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid post ID")})
+		return post, false
+	}
+	if err := initializers.DB.Scopes(visiblePosts(c)).First(&post, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Post not found")})
+		return post, false
+	}
+	return post, true
+}
+
+// findComment loads the comment in the :commentId parameter on the post, writing a 400 or 404 on failure
+func findComment(c *gin.Context, post models.Post) (models.Comment, bool) {
+	var comment models.Comment
+	id, err := strconv.Atoi(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid comment ID")})
+		return comment, false
+	}
+	if err := initializers.DB.Scopes(visibleComments(c)).Where("post_id = ?", post.ID).First(&comment, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Comment not found")})
+		return comment, false
+	}
+	return comment, true
+}
+
+// deleteCommentThread deletes a comment and its replies
+func deleteCommentThread(tx *gorm.DB, comment models.Comment) error {
+	if err := tx.Where("parent_id = ?", comment.ID).Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&comment).Error
+}
+
+// GetComments lists a post's top-level comments, oldest first, each with all of its replies
+func GetComments(c *gin.Context) {
+	post, ok := findPostByParam(c)
+	if !ok {
+		return
+	}
+	page, limit, offset, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	topLevel := initializers.DB.Model(&models.Comment{}).Scopes(visibleComments(c)).Where("post_id = ? AND parent_id IS NULL", post.ID)
+	var total int64
+	if err := topLevel.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch comments")})
+		return
+	}
+
+	var comments []models.Comment
+	if err := topLevel.Preload("User").Order("created_at ASC, id ASC").Limit(limit).Offset(offset).Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch comments")})
+		return
+	}
+
+	commentIDs := make([]uint, len(comments))
+	for i, comment := range comments {
+		commentIDs[i] = comment.ID
+	}
+	var replies []models.Comment
+	if len(commentIDs) > 0 {
+		if err := initializers.DB.Scopes(visibleComments(c)).Preload("User").Where("parent_id IN ?", commentIDs).Order("created_at ASC, id ASC").Find(&replies).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch comments")})
+			return
+		}
+	}
+	repliesByParent := map[uint][]CommentResponse{}
+	for _, reply := range replies {
+		repliesByParent[*reply.ParentID] = append(repliesByParent[*reply.ParentID], toCommentResponse(reply))
+	}
+
+	commentResponses := []CommentResponse{}
+	for _, comment := range comments {
+		response := toCommentResponse(comment)
+		response.Replies = repliesByParent[comment.ID]
+		commentResponses = append(commentResponses, response)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments": commentResponses,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// CreateComment comments on a post, or replies to a comment when parentID is
+// set. Replies to a reply join the same thread. The post author and the
+// author of the comment replied to are notified.
+func CreateComment(c *gin.Context) {
+	post, ok := findPostByParam(c)
+	if !ok {
+		return
+	}
+
+	var input commentRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	body, ok := validateCommentBody(c, input.Body)
+	if !ok {
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	comment := models.Comment{PostID: post.ID, UserID: user.ID, User: user, Body: body}
+
+	var parent models.Comment
+	if input.ParentID != nil {
+		if err := initializers.DB.Scopes(visibleComments(c)).Where("post_id = ?", post.ID).First(&parent, *input.ParentID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid parent comment")})
+			return
+		}
+		comment.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+
+	if err := initializers.DB.Omit("User").Create(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to create comment: ") + err.Error()})
+		return
+	}
+
+	data := map[string]interface{}{"Name": user.Name, "Title": post.Title}
+	link := fmt.Sprintf("/api/posts/%d/comments", post.ID)
+	if post.UserID != user.ID {
+		notifications.DispatchAsync([]uint{post.UserID}, notifications.Message{
+			Category: models.NotificationCategorySocial,
+			Template: helpers.MessageCommentAdded,
+			Data:     data,
+			Link:     link,
+		})
+	}
+	if input.ParentID != nil && parent.UserID != user.ID && parent.UserID != post.UserID {
+		notifications.DispatchAsync([]uint{parent.UserID}, notifications.Message{
+			Category: models.NotificationCategorySocial,
+			Template: helpers.MessageCommentReply,
+			Data:     data,
+			Link:     link,
+		})
+	}
+
+	c.JSON(http.StatusCreated, toCommentResponse(comment))
+}
+
+// UpdateComment edits a comment's body. Only its author can edit it.
+func UpdateComment(c *gin.Context) {
+	post, ok := findPostByParam(c)
+	if !ok {
+		return
+	}
+	comment, ok := findComment(c, post)
+	if !ok {
+		return
+	}
+
+	if comment.UserID != c.GetUint("userID") {
+		forbid(c, "you can only edit your own comments")
+		return
+	}
+
+	var input commentRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	body, ok := validateCommentBody(c, input.Body)
+	if !ok {
+		return
+	}
+
+	comment.Body = body
+	if err := initializers.DB.Save(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update comment")})
+		return
+	}
+
+	comment.User = c.MustGet("user").(models.User)
+	c.JSON(http.StatusOK, toCommentResponse(comment))
+}
+
+// DeleteComment removes a comment and, for a top-level comment, its replies.
+// Its author, moderators and admins can delete it.
+func DeleteComment(c *gin.Context) {
+	post, ok := findPostByParam(c)
+	if !ok {
+		return
+	}
+	comment, ok := findComment(c, post)
+	if !ok {
+		return
+	}
+
+	if comment.UserID != c.GetUint("userID") && !callerCan(c, models.PermModeratePosts) {
+		forbid(c, "only the author, a moderator or an admin can delete this comment")
+		return
+	}
+
+	if err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteCommentThread(tx, comment)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to delete comment")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
@@ -1,18 +1,21 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
 	initializers "github.com/group4/campus-connect-api/Initializers"
 	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
 )
 
 func GetEvents(c *gin.Context) {
 	var events []models.Event
 	if err := initializers.DB.Find(&events).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch events")})
 		return
 	}
 	c.JSON(http.StatusOK, events)
@@ -21,12 +24,12 @@ func GetEvents(c *gin.Context) {
 func CreateEvent(c *gin.Context) {
 	var event models.Event
 	if err := c.ShouldBindJSON(&event); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
 		return
 	}
 
 	if err := initializers.DB.Create(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to create event: ") + err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, event)
@@ -35,13 +38,13 @@ func CreateEvent(c *gin.Context) {
 func GetEventByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid event ID")})
 		return
 	}
 
 	var event models.Event
 	if err := initializers.DB.First(&event, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Event not found")})
 		return
 	}
 	c.JSON(http.StatusOK, event)
@@ -50,22 +53,31 @@ func GetEventByID(c *gin.Context) {
 func UpdateEvent(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid event ID")})
 		return
 	}
 
 	var event models.Event
 	if err := initializers.DB.First(&event, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Event not found")})
 		return
 	}
 
 	var updatedEvent models.Event
 	if err := c.ShouldBindJSON(&updatedEvent); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
 		return
 	}
 
+	// Dates are compared with Equal: one loaded from the database and one parsed
+	// from JSON differ in location even when they are the same instant
+	rescheduled := !event.Date.Equal(updatedEvent.Date)
+	changed := rescheduled || event.Title != updatedEvent.Title
+	// A rescheduled event gets a fresh reminder
+	if rescheduled {
+		event.ReminderSentAt = nil
+	}
+
 	event.Quarter = updatedEvent.Quarter
 	event.Month = updatedEvent.Month
 	event.Date = updatedEvent.Date
@@ -73,28 +85,89 @@ func UpdateEvent(c *gin.Context) {
 	event.Participants = updatedEvent.Participants
 
 	if err := initializers.DB.Save(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update event")})
 		return
 	}
+
+	if changed {
+		notifications.DispatchAsync(eventFollowerIDs(event.ID), notifications.Message{
+			Category: models.NotificationCategoryEvents,
+			Template: helpers.MessageEventUpdated,
+			Data:     map[string]interface{}{"Title": event.Title, "Date": event.Date},
+			Link:     fmt.Sprintf("/api/events/%d", event.ID),
+		})
+	}
 	c.JSON(http.StatusOK, event)
 }
 
 func DeleteEvent(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid event ID")})
 		return
 	}
 
 	var event models.Event
 	if err := initializers.DB.First(&event, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Event not found")})
 		return
 	}
 
+	followerIDs := eventFollowerIDs(event.ID)
+
 	if err := initializers.DB.Delete(&event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to delete event")})
 		return
 	}
+
+	notifications.DispatchAsync(followerIDs, notifications.Message{
+		Category: models.NotificationCategoryEvents,
+		Template: helpers.MessageEventCancelled,
+		Data:     map[string]interface{}{"Title": event.Title, "Date": event.Date},
+	})
 	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
+}
+
+// FollowEvent RSVPs the caller to an event so they are notified of changes
+func FollowEvent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid event ID")})
+		return
+	}
+
+	var event models.Event
+	if err := initializers.DB.First(&event, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Event not found")})
+		return
+	}
+
+	follower := models.EventFollower{EventID: event.ID, UserID: c.GetUint("userID")}
+	if err := initializers.DB.Where(&follower).FirstOrCreate(&follower).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to follow event")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Following event"})
+}
+
+func UnfollowEvent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid event ID")})
+		return
+	}
+
+	if err := initializers.DB.Unscoped().
+		Where("event_id = ? AND user_id = ?", id, c.GetUint("userID")).
+		Delete(&models.EventFollower{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to unfollow event")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Unfollowed event"})
+}
+
+func eventFollowerIDs(eventID uint) []uint {
+	var userIDs []uint
+	initializers.DB.Model(&models.EventFollower{}).Where("event_id = ?", eventID).Pluck("user_id", &userIDs)
+	return userIDs
 }
\ No newline at end of file
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
+)
+
+// Feed modes
+const (
+	FeedLatest = "latest"
+	FeedRanked = "ranked"
+)
+
+const (
+	// rankWindow limits the ranked feed to recent posts so scoring stays cheap
+	rankWindow = 14 * 24 * time.Hour
+	// engagementWindow is how far back comments and reactions count towards a post's score
+	engagementWindow = 72 * time.Hour
+)
+
+// rankScoreSQL scores a post for the viewer: one point plus its recent
+// comments and reactions, doubled for the viewer's course and raised by half
+// for their year, decaying with the hours since the post was published. Arguments: the
+// viewer's course, the viewer's year and the ranking time.
+const rankScoreSQL = `CAST(
+	(1 + COALESCE(engagement.total, 0))
+	* (1
+		+ CASE WHEN users.course <> '' AND users.course = ? THEN 1 ELSE 0 END
+		+ CASE WHEN users.year <> '' AND users.year = ? THEN 0.5 ELSE 0 END)
+	/ POWER(EXTRACT(EPOCH FROM (CAST(? AS timestamptz) - posts.publish_at)) / 3600 + 2, 1.5)
+AS double precision)`
+
+// engagementSQL counts each post's comments and reactions made in a window.
+// Arguments: the window's start and end, once for comments and once for reactions.
+const engagementSQL = `SELECT post_id, COUNT(*) AS total FROM (
+	SELECT post_id FROM comments WHERE deleted_at IS NULL AND hidden_at IS NULL AND created_at > ? AND created_at <= ?
+	UNION ALL
+	SELECT post_id FROM post_reactions WHERE deleted_at IS NULL AND created_at > ? AND created_at <= ?
+) AS interactions GROUP BY post_id`
+
+// feedCursor is the position after the last post of a feed page. Ranked
+// cursors carry the ranking time so every page is scored against the same clock.
+type feedCursor struct {
+	Mode      string    `json:"m"`
+	ID        uint      `json:"i"`
+	PublishAt time.Time `json:"p"`
+	Score     float64   `json:"s"`
+	RankedAt  time.Time `json:"r"`
+}
+
+// GetFeed pages through posts with a ?cursor= from the previous page's
+// nextCursor. ?mode=latest (the default) lists newest first; ?mode=ranked
+// favours recent engagement and posts from the viewer's course and year.
+func GetFeed(c *gin.Context) {
+	limit, ok := limitParam(c)
+	if !ok {
+		return
+	}
+
+	mode := c.DefaultQuery("mode", FeedLatest)
+	if mode != FeedLatest && mode != FeedRanked {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid mode: must be latest or ranked")})
+		return
+	}
+
+	cursor, ok := feedCursorParam(c, mode)
+	if !ok {
+		return
+	}
+
+	var posts []models.Post
+	var next *feedCursor
+	var err error
+	if mode == FeedRanked {
+		var viewer models.User
+		if user, exists := c.Get("user"); exists {
+			viewer = user.(models.User)
+		}
+		posts, next, err = rankedFeedPage(initializers.DB.Scopes(publishedPosts(c)), viewer, cursor, limit)
+	} else {
+		posts, next, err = latestFeedPage(initializers.DB.Scopes(publishedPosts(c)), cursor, limit)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch feed")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts":      toPostResponses(posts, c.GetUint("userID")),
+		"mode":       mode,
+		"nextCursor": encodeFeedCursor(next),
+	})
+}
+
+// feedCursorParam reads ?cursor= for the mode, writing a 400 and returning
+// ok=false when it is malformed or from another mode. The cursor is nil on
+// the first page.
+func feedCursorParam(c *gin.Context, mode string) (*feedCursor, bool) {
+	raw := c.Query("cursor")
+	if raw == "" {
+		return nil, true
+	}
+	cursor := &feedCursor{}
+	if err := decodeCursor(raw, cursor); err != nil || cursor.Mode != mode {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid cursor")})
+		return nil, false
+	}
+	return cursor, true
+}
+
+// encodeFeedCursor returns the nextCursor value for a page, null on the last page
+func encodeFeedCursor(next *feedCursor) *string {
+	if next == nil {
+		return nil
+	}
+	encoded := encodeCursor(next)
+	return &encoded
+}
+
+// latestFeedPage reads the page of published posts matching query after the
+// cursor, most recently published first. The next cursor is nil on the last page.
+func latestFeedPage(query *gorm.DB, cursor *feedCursor, limit int) ([]models.Post, *feedCursor, error) {
+	query = query.Preload("User").Order("publish_at DESC, id DESC").Limit(limit + 1)
+	if cursor != nil {
+		query = query.Where("publish_at < ? OR (publish_at = ? AND id < ?)", cursor.PublishAt, cursor.PublishAt, cursor.ID)
+	}
+
+	var posts []models.Post
+	if err := query.Find(&posts).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(posts) <= limit {
+		return posts, nil, nil
+	}
+
+	posts = posts[:limit]
+	last := posts[limit-1]
+	return posts, &feedCursor{Mode: FeedLatest, ID: last.ID, PublishAt: *last.PublishAt}, nil
+}
+
+// rankedFeedPage scores published posts matching query from the last
+// rankWindow for the viewer and reads the page after the cursor, highest score
+// first. The next cursor is nil on the last page.
+func rankedFeedPage(query *gorm.DB, viewer models.User, cursor *feedCursor, limit int) ([]models.Post, *feedCursor, error) {
+	rankedAt := time.Now()
+	if cursor != nil {
+		rankedAt = cursor.RankedAt
+	}
+	since := rankedAt.Add(-engagementWindow)
+
+	scored := query.Table("posts").
+		Select("posts.id, "+rankScoreSQL+" AS score", viewer.Course, viewer.Year, rankedAt).
+		Joins("LEFT JOIN users ON users.id = posts.user_id").
+		Joins("LEFT JOIN ("+engagementSQL+") AS engagement ON engagement.post_id = posts.id", since, rankedAt, since, rankedAt).
+		Where("posts.deleted_at IS NULL AND posts.publish_at > ? AND posts.publish_at <= ?", rankedAt.Add(-rankWindow), rankedAt)
+
+	page := initializers.DB.Table("(?) AS ranked", scored).Order("score DESC, id DESC").Limit(limit + 1)
+	if cursor != nil {
+		page = page.Where("score < ? OR (score = ? AND id < ?)", cursor.Score, cursor.Score, cursor.ID)
+	}
+
+	var ranked []struct {
+		ID    uint
+		Score float64
+	}
+	if err := page.Scan(&ranked).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var next *feedCursor
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+		last := ranked[limit-1]
+		next = &feedCursor{Mode: FeedRanked, ID: last.ID, Score: last.Score, RankedAt: rankedAt}
+	}
+
+	postIDs := make([]uint, len(ranked))
+	for i, row := range ranked {
+		postIDs[i] = row.ID
+	}
+	var found []models.Post
+	if len(postIDs) > 0 {
+		if err := initializers.DB.Preload("User").Where("id IN ?", postIDs).Find(&found).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Restore the ranking order lost by the IN query
+	byID := map[uint]models.Post{}
+	for _, post := range found {
+		byID[post.ID] = post
+	}
+	posts := []models.Post{}
+	for _, id := range postIDs {
+		if post, ok := byID[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, next, nil
+}
@@ -1,18 +1,21 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
 	initializers "github.com/group4/campus-connect-api/Initializers"
 	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
 )
 
 func GetJobs(c *gin.Context) {
 	var jobs []models.Job
 	if err := initializers.DB.Find(&jobs).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch jobs")})
 		return
 	}
 	c.JSON(http.StatusOK, jobs)
@@ -21,27 +24,37 @@ func GetJobs(c *gin.Context) {
 func CreateJob(c *gin.Context) {
 	var job models.Job
 	if err := c.ShouldBindJSON(&job); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
 		return
 	}
 
 	if err := initializers.DB.Create(&job).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to create job: ") + err.Error()})
 		return
 	}
+
+	// Tell everyone else about the new job, on the channels they chose for jobs
+	var userIDs []uint
+	initializers.DB.Model(&models.User{}).Where("id <> ?", c.GetUint("userID")).Pluck("id", &userIDs)
+	notifications.DispatchAsync(userIDs, notifications.Message{
+		Category: models.NotificationCategoryJobs,
+		Template: helpers.MessageJobPosted,
+		Data:     map[string]interface{}{"Title": job.Title, "Company": job.Company},
+		Link:     fmt.Sprintf("/api/jobs/%d", job.ID),
+	})
 	c.JSON(http.StatusCreated, job)
 }
 
 func GetJobByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid job ID")})
 		return
 	}
 
 	var job models.Job
 	if err := initializers.DB.First(&job, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Job not found")})
 		return
 	}
 	c.JSON(http.StatusOK, job)
@@ -50,19 +63,19 @@ func GetJobByID(c *gin.Context) {
 func UpdateJob(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid job ID")})
 		return
 	}
 
 	var job models.Job
 	if err := initializers.DB.First(&job, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Job not found")})
 		return
 	}
 
 	var updatedJob models.Job
 	if err := c.ShouldBindJSON(&updatedJob); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
 		return
 	}
 
@@ -72,7 +85,7 @@ func UpdateJob(c *gin.Context) {
 	job.Link = updatedJob.Link
 
 	if err := initializers.DB.Save(&job).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update job"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update job")})
 		return
 	}
 	c.JSON(http.StatusOK, job)
@@ -81,18 +94,18 @@ func UpdateJob(c *gin.Context) {
 func DeleteJob(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid job ID")})
 		return
 	}
 
 	var job models.Job
 	if err := initializers.DB.First(&job, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Job not found")})
 		return
 	}
 
 	if err := initializers.DB.Delete(&job).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete job"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to delete job")})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Job deleted successfully"})
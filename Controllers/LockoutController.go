@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
+)
+
+const (
+	loginMaxFailures     = 5
+	loginLockoutDuration = 15 * time.Minute
+	loginBackoffBase     = time.Second
+	loginIPWindow        = 15 * time.Minute
+	loginIPMaxFailures   = 20
+)
+
+// loginRetryAfter returns how long the caller must wait before another login
+// attempt because of failures from their IP
+func loginRetryAfter(ip string) time.Duration {
+	var ipFailures int64
+	initializers.DB.Model(&models.LoginAttempt{}).
+		Where("ip_address = ? AND succeeded = ? AND created_at > ?", ip, false, time.Now().Add(-loginIPWindow)).
+		Count(&ipFailures)
+	if ipFailures >= loginIPMaxFailures {
+		return loginIPWindow
+	}
+	return 0
+}
+
+// accountRetryAfter returns how long the account's failure streak makes the
+// next attempt wait, doubling with each failure: 1s, 2s, 4s, ...
+func accountRetryAfter(user models.User) time.Duration {
+	if user.FailedLogins == 0 || user.LastFailedLoginAt == nil {
+		return 0
+	}
+	backoff := loginBackoffBase * time.Duration(math.Pow(2, float64(user.FailedLogins-1)))
+	if remaining := user.LastFailedLoginAt.Add(backoff).Sub(time.Now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// recordLoginAttempt stores the attempt and, for known accounts, updates the
+// failure streak, locking the account once it reaches loginMaxFailures
+func recordLoginAttempt(user *models.User, email, ip string, succeeded bool) {
+	initializers.DB.Create(&models.LoginAttempt{Email: email, IPAddress: ip, Succeeded: succeeded})
+	if user == nil {
+		return
+	}
+
+	if succeeded {
+		initializers.DB.Model(user).Updates(map[string]interface{}{
+			"failed_logins":        0,
+			"last_failed_login_at": nil,
+			"locked_until":         nil,
+		})
+		return
+	}
+
+	now := time.Now()
+	initializers.DB.Model(user).Updates(map[string]interface{}{
+		"failed_logins":        gorm.Expr("failed_logins + 1"),
+		"last_failed_login_at": now,
+	})
+	initializers.DB.First(user, user.ID)
+	if user.FailedLogins < loginMaxFailures {
+		return
+	}
+
+	lockedUntil := now.Add(loginLockoutDuration)
+	initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"failed_logins":        0,
+			"last_failed_login_at": nil,
+			"locked_until":         lockedUntil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AccountLockout{
+			UserID:         user.ID,
+			Email:          user.Email,
+			IPAddress:      ip,
+			FailedAttempts: loginMaxFailures,
+			LockedUntil:    lockedUntil,
+		}).Error
+	})
+	user.LockedUntil = &lockedUntil
+}
+
+// tooManyLoginAttempts writes a 429 with a Retry-After header
+func tooManyLoginAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":      localize(c, "Too many login attempts, try again later"),
+		"retryAfter": seconds,
+	})
+}
+
+// accountLocked answers a login to a locked or backing-off account exactly like
+// one with unknown credentials, so the response does not reveal that the email
+// is registered
+func accountLocked(c *gin.Context) {
+	c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid credentials")})
+}
+
+// UnlockUser lets an admin clear a lockout before it expires
+func UnlockUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid user ID")})
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "User not found")})
+		return
+	}
+
+	adminID := c.GetUint("userID")
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"failed_logins":        0,
+			"last_failed_login_at": nil,
+			"locked_until":         nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.AccountLockout{}).
+			Where("user_id = ? AND unlocked_at IS NULL AND locked_until > ?", user.ID, time.Now()).
+			Updates(map[string]interface{}{"unlocked_at": time.Now(), "unlocked_by_id": adminID}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to unlock user")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// GetLockouts lists recorded lockouts, newest first, optionally filtered by ?email= or ?ip=
+func GetLockouts(c *gin.Context) {
+	query := initializers.DB.Order("created_at DESC").Limit(200)
+	if email := strings.TrimSpace(c.Query("email")); email != "" {
+		query = query.Where("email = ?", email)
+	}
+	if ip := strings.TrimSpace(c.Query("ip")); ip != "" {
+		query = query.Where("ip_address = ?", ip)
+	}
+
+	var lockouts []models.AccountLockout
+	if err := query.Find(&lockouts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch lockouts")})
+		return
+	}
+	c.JSON(http.StatusOK, lockouts)
+}
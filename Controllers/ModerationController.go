@@ -0,0 +1,469 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxReportDetails      = 1000
+	defaultSuspensionDays = 7
+	maxSuspensionDays     = 365
+)
+
+type ReportResponse struct {
+	ID           uint             `json:"id"`
+	TargetType   string           `json:"targetType"`
+	TargetID     uint             `json:"targetID"`
+	Reason       string           `json:"reason"`
+	Details      string           `json:"details"`
+	Status       string           `json:"status"`
+	Action       string           `json:"action"`
+	Reporter     UserResponse     `json:"reporter"`
+	ResolvedByID *uint            `json:"resolvedByID"`
+	ResolvedAt   *time.Time       `json:"resolvedAt"`
+	CreatedAt    time.Time        `json:"createdAt"`
+	OpenReports  int64            `json:"openReports"`
+	Post         *PostResponse    `json:"post,omitempty"`
+	Comment      *CommentResponse `json:"comment,omitempty"`
+}
+
+type reportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+type moderationRequest struct {
+	Action string `json:"action"`
+	Note   string `json:"note"`
+	Days   int    `json:"days"`
+}
+
+// ReportPost flags a post for the moderation queue
+func ReportPost(c *gin.Context) {
+	post, ok := findPostByParam(c)
+	if !ok {
+		return
+	}
+	submitReport(c, models.ReportTargetPost, post.ID, post.UserID)
+}
+
+// ReportComment flags a comment for the moderation queue
+func ReportComment(c *gin.Context) {
+	post, ok := findPostByParam(c)
+	if !ok {
+		return
+	}
+	comment, ok := findComment(c, post)
+	if !ok {
+		return
+	}
+	submitReport(c, models.ReportTargetComment, comment.ID, comment.UserID)
+}
+
+// submitReport stores the caller's report on a post or comment. Each user can
+// report the same content once, and never their own.
+func submitReport(c *gin.Context, targetType string, targetID, authorID uint) {
+	var input reportRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	reason := strings.ToLower(strings.TrimSpace(input.Reason))
+	if !models.ValidReportReason(reason) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid reason: must be one of ") + strings.Join(models.ReportReasons, ", ")})
+		return
+	}
+	details := strings.TrimSpace(input.Details)
+	if utf8.RuneCountInString(details) > maxReportDetails {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Details are too long: max ") + strconv.Itoa(maxReportDetails)})
+		return
+	}
+
+	reporterID := c.GetUint("userID")
+	if authorID == reporterID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "You cannot report your own content")})
+		return
+	}
+
+	report := models.Report{
+		ReporterID: reporterID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		Details:    details,
+		Status:     models.ReportStatusOpen,
+	}
+	result := initializers.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&report)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to submit report")})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "You have already reported this content")})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Report submitted", "id": report.ID})
+}
+
+// GetReports is the moderation queue. It lists reports with ?status= (default
+// open, oldest first; others newest first), optionally only of one ?type=,
+// each with the reported content and how many open reports it has.
+func GetReports(c *gin.Context) {
+	page, limit, offset, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	status := c.DefaultQuery("status", models.ReportStatusOpen)
+	if status != models.ReportStatusOpen && status != models.ReportStatusResolved && status != models.ReportStatusDismissed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid status: ") + status})
+		return
+	}
+	query := initializers.DB.Model(&models.Report{}).Where("status = ?", status)
+	if targetType := c.Query("type"); targetType != "" {
+		if targetType != models.ReportTargetPost && targetType != models.ReportTargetComment {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid type: must be post or comment")})
+			return
+		}
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch reports")})
+		return
+	}
+
+	order := "created_at ASC, id ASC"
+	if status != models.ReportStatusOpen {
+		order = "resolved_at DESC, id DESC"
+	}
+	var reports []models.Report
+	if err := query.Preload("Reporter").Order(order).Limit(limit).Offset(offset).Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch reports")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reports": toReportResponses(reports, c.GetUint("userID")),
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// toReportResponses attaches the reported content, deleted or not, and its
+// open report count to each report, loading them for the whole page at once
+func toReportResponses(reports []models.Report, viewerID uint) []ReportResponse {
+	var postIDs, commentIDs []uint
+	for _, report := range reports {
+		if report.TargetType == models.ReportTargetPost {
+			postIDs = append(postIDs, report.TargetID)
+		} else {
+			commentIDs = append(commentIDs, report.TargetID)
+		}
+	}
+
+	posts := map[uint]PostResponse{}
+	if len(postIDs) > 0 {
+		var found []models.Post
+		initializers.DB.Unscoped().Preload("User").Where("id IN ?", postIDs).Find(&found)
+		for _, post := range toPostResponses(found, viewerID) {
+			posts[post.ID] = post
+		}
+	}
+	comments := map[uint]CommentResponse{}
+	if len(commentIDs) > 0 {
+		var found []models.Comment
+		initializers.DB.Unscoped().Preload("User").Where("id IN ?", commentIDs).Find(&found)
+		for _, comment := range found {
+			comments[comment.ID] = toCommentResponse(comment)
+		}
+	}
+
+	var counts []struct {
+		TargetType string
+		TargetID   uint
+		Count      int64
+	}
+	initializers.DB.Model(&models.Report{}).
+		Select("target_type, target_id, COUNT(*) AS count").
+		Where("status = ?", models.ReportStatusOpen).
+		Where("(target_type = ? AND target_id IN ?) OR (target_type = ? AND target_id IN ?)",
+			models.ReportTargetPost, postIDs, models.ReportTargetComment, commentIDs).
+		Group("target_type, target_id").
+		Scan(&counts)
+	openReports := map[string]int64{}
+	for _, count := range counts {
+		openReports[fmt.Sprintf("%s:%d", count.TargetType, count.TargetID)] = count.Count
+	}
+
+	responses := []ReportResponse{}
+	for _, report := range reports {
+		response := ReportResponse{
+			ID:           report.ID,
+			TargetType:   report.TargetType,
+			TargetID:     report.TargetID,
+			Reason:       report.Reason,
+			Details:      report.Details,
+			Status:       report.Status,
+			Action:       report.Action,
+			Reporter:     toUserResponse(report.Reporter),
+			ResolvedByID: report.ResolvedByID,
+			ResolvedAt:   report.ResolvedAt,
+			CreatedAt:    report.CreatedAt,
+			OpenReports:  openReports[fmt.Sprintf("%s:%d", report.TargetType, report.TargetID)],
+		}
+		if post, ok := posts[report.TargetID]; ok && report.TargetType == models.ReportTargetPost {
+			response.Post = &post
+		}
+		if comment, ok := comments[report.TargetID]; ok && report.TargetType == models.ReportTargetComment {
+			response.Comment = &comment
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// ModerateReport applies a moderation action to the reported content or its
+// author, and closes every open report on that content. The author is notified
+// of everything but restore and dismiss.
+func ModerateReport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid report ID")})
+		return
+	}
+	var report models.Report
+	if err := initializers.DB.First(&report, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Report not found")})
+		return
+	}
+
+	var input moderationRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	action := strings.ToLower(strings.TrimSpace(input.Action))
+	validAction := false
+	for _, a := range models.ModerationActions {
+		validAction = validAction || a == action
+	}
+	if !validAction {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid action: must be one of ") + strings.Join(models.ModerationActions, ", ")})
+		return
+	}
+	note := strings.TrimSpace(input.Note)
+
+	// Load the reported content, including deleted content so warnings and
+	// suspensions still work after the author deletes it
+	var post models.Post
+	var comment models.Comment
+	var content interface{}
+	var deleted bool
+	var authorID uint
+	var title, link string
+	if report.TargetType == models.ReportTargetPost {
+		if err := initializers.DB.Unscoped().First(&post, report.TargetID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Reported content not found")})
+			return
+		}
+		content, deleted, authorID = &post, post.DeletedAt.Valid, post.UserID
+		title, link = post.Title, fmt.Sprintf("/api/posts/%d", post.ID)
+	} else {
+		if err := initializers.DB.Unscoped().First(&comment, report.TargetID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Reported content not found")})
+			return
+		}
+		content, deleted, authorID = &comment, comment.DeletedAt.Valid, comment.UserID
+		title, link = excerpt(comment.Body, 60), fmt.Sprintf("/api/posts/%d/comments", comment.PostID)
+	}
+	if deleted && (action == models.ModerationHide || action == models.ModerationRestore || action == models.ModerationDelete) {
+		c.JSON(http.StatusConflict, gin.H{"error": localize(c, "Reported content has already been deleted")})
+		return
+	}
+
+	var author models.User
+	if err := initializers.DB.First(&author, authorID).Error; err != nil && (action == models.ModerationWarn || action == models.ModerationSuspend) {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "User not found")})
+		return
+	}
+
+	days := input.Days
+	if action == models.ModerationSuspend {
+		if days == 0 {
+			days = defaultSuspensionDays
+		}
+		if days < 1 || days > maxSuspensionDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid days: must be between 1 and ") + strconv.Itoa(maxSuspensionDays)})
+			return
+		}
+		if models.HasPermission(author.Role, models.PermModeratePosts) {
+			forbid(c, "moderators and admins cannot be suspended")
+			return
+		}
+	}
+
+	now := time.Now()
+	moderatorID := c.GetUint("userID")
+	var suspendedUntil *time.Time
+	var resolved int64
+	var imagePaths []string
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		switch action {
+		case models.ModerationHide:
+			if err := tx.Model(content).Where("hidden_at IS NULL").Update("hidden_at", now).Error; err != nil {
+				return err
+			}
+		case models.ModerationRestore:
+			if err := tx.Model(content).Update("hidden_at", nil).Error; err != nil {
+				return err
+			}
+		case models.ModerationDelete:
+			var err error
+			if report.TargetType == models.ReportTargetPost {
+				imagePaths, err = deletePostRecords(tx, post)
+			} else {
+				err = deleteCommentThread(tx, comment)
+			}
+			if err != nil {
+				return err
+			}
+		case models.ModerationSuspend:
+			until := now.AddDate(0, 0, days)
+			suspendedUntil = &until
+			if err := tx.Model(&author).Update("suspended_until", until).Error; err != nil {
+				return err
+			}
+			if err := revokeUserSessions(tx, author.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&models.ModerationAction{
+			ModeratorID:    moderatorID,
+			TargetType:     report.TargetType,
+			TargetID:       report.TargetID,
+			UserID:         authorID,
+			Action:         action,
+			Note:           note,
+			SuspendedUntil: suspendedUntil,
+		}).Error; err != nil {
+			return err
+		}
+
+		status := models.ReportStatusResolved
+		if action == models.ModerationDismiss {
+			status = models.ReportStatusDismissed
+		}
+		result := tx.Model(&models.Report{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, models.ReportStatusOpen).
+			Updates(map[string]interface{}{
+				"status":         status,
+				"action":         action,
+				"resolved_by_id": moderatorID,
+				"resolved_at":    now,
+			})
+		resolved = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to apply moderation action")})
+		return
+	}
+
+	deleteImageFiles(imagePaths)
+
+	templates := map[string]string{
+		models.ModerationHide:    helpers.MessageContentHidden,
+		models.ModerationDelete:  helpers.MessageContentRemoved,
+		models.ModerationWarn:    helpers.MessageModerationWarning,
+		models.ModerationSuspend: helpers.MessageAccountSuspended,
+	}
+	if template, ok := templates[action]; ok && author.ID != 0 {
+		if action == models.ModerationDelete || action == models.ModerationSuspend {
+			link = ""
+		}
+		data := map[string]interface{}{"Title": title, "Note": note}
+		if suspendedUntil != nil {
+			data["Until"] = *suspendedUntil
+		}
+		notifications.DispatchAsync([]uint{author.ID}, notifications.Message{
+			Category: models.NotificationCategoryModeration,
+			Template: template,
+			Data:     data,
+			Link:     link,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Moderation action applied",
+		"action":          action,
+		"resolvedReports": resolved,
+		"suspendedUntil":  suspendedUntil,
+	})
+}
+
+// UnsuspendUser lifts a suspension before it ends
+func UnsuspendUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid user ID")})
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "User not found")})
+		return
+	}
+
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("suspended_until", nil).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ModerationAction{
+			ModeratorID: c.GetUint("userID"),
+			TargetType:  models.ModerationTargetUser,
+			TargetID:    user.ID,
+			UserID:      user.ID,
+			Action:      models.ModerationUnsuspend,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to unsuspend user")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User unsuspended successfully"})
+}
+
+// accountSuspended writes the 403 returned to suspended accounts
+func accountSuspended(c *gin.Context, user models.User) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":          localize(c, "Account suspended"),
+		"suspendedUntil": user.SuspendedUntil,
+	})
+}
+
+// excerpt shortens text to at most n characters for notifications
+func excerpt(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	return string([]rune(text)[:n-1]) + "…"
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+type NotificationResponse struct {
+	ID        uint       `json:"id"`
+	Category  string     `json:"category"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      string     `json:"link"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// GetNotifications lists the caller's notifications, newest first. Pass ?unread=true to skip read ones.
+func GetNotifications(c *gin.Context) {
+	page, limit, offset, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("userID")
+	query := initializers.DB.Where("user_id = ?", userID)
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch notifications")})
+		return
+	}
+
+	var unreadCount int64
+	initializers.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unreadCount)
+
+	notificationResponses := []NotificationResponse{}
+	for _, notification := range notifications {
+		notificationResponses = append(notificationResponses, NotificationResponse{
+			ID:        notification.ID,
+			Category:  notification.Category,
+			Title:     notification.Title,
+			Body:      notification.Body,
+			Link:      notification.Link,
+			Read:      notification.ReadAt != nil,
+			ReadAt:    notification.ReadAt,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notificationResponses,
+		"unreadCount":   unreadCount,
+		"page":          page,
+		"limit":         limit,
+	})
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid notification ID")})
+		return
+	}
+
+	var notification models.Notification
+	if err := initializers.DB.Where("user_id = ?", c.GetUint("userID")).First(&notification, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Notification not found")})
+		return
+	}
+
+	if notification.ReadAt == nil {
+		if err := initializers.DB.Model(&notification).Update("read_at", time.Now()).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update notification")})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func MarkAllNotificationsRead(c *gin.Context) {
+	result := initializers.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", c.GetUint("userID")).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update notifications")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read", "updated": result.RowsAffected})
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
+)
+
+type ChannelSettings struct {
+	InApp bool `json:"inApp"`
+	SMS   bool `json:"sms"`
+	Email bool `json:"email"`
+}
+
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+type NotificationPreferencesResponse struct {
+	Categories map[string]ChannelSettings `json:"categories"`
+	QuietHours QuietHours                 `json:"quietHours"`
+}
+
+func toNotificationPreferencesResponse(preference models.NotificationPreference) NotificationPreferencesResponse {
+	categories := map[string]ChannelSettings{}
+	for _, category := range models.NotificationCategories {
+		inApp, sms, email := preference.Channels(category)
+		categories[category] = ChannelSettings{InApp: inApp, SMS: sms, Email: email}
+	}
+	return NotificationPreferencesResponse{
+		Categories: categories,
+		QuietHours: QuietHours{
+			Start:    preference.QuietHoursStart,
+			End:      preference.QuietHoursEnd,
+			Timezone: preference.Timezone,
+		},
+	}
+}
+
+func GetNotificationPreferences(c *gin.Context) {
+	userID := c.GetUint("userID")
+	preferences, err := notifications.PreferencesFor([]uint{userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch notification preferences")})
+		return
+	}
+	c.JSON(http.StatusOK, toNotificationPreferencesResponse(preferences[userID]))
+}
+
+// UpdateNotificationPreferences changes only the categories and quiet hours present in the body
+func UpdateNotificationPreferences(c *gin.Context) {
+	type UpdatePreferencesRequest struct {
+		Categories map[string]ChannelSettings `json:"categories"`
+		QuietHours *QuietHours                `json:"quietHours"`
+	}
+
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	userID := c.GetUint("userID")
+	preferences, err := notifications.PreferencesFor([]uint{userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch notification preferences")})
+		return
+	}
+	preference := preferences[userID]
+
+	for category, settings := range req.Categories {
+		if !validCategory(category) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid category: ") + category})
+			return
+		}
+		preference.SetChannels(category, settings.InApp, settings.SMS, settings.Email)
+	}
+
+	if req.QuietHours != nil {
+		quiet := *req.QuietHours
+		if (quiet.Start == "") != (quiet.End == "") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid quiet hours: set both start and end, or neither")})
+			return
+		}
+		if quiet.Start != "" {
+			if _, err := models.ParseClock(quiet.Start); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid quiet hours: ") + err.Error()})
+				return
+			}
+			if _, err := models.ParseClock(quiet.End); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid quiet hours: ") + err.Error()})
+				return
+			}
+		}
+		if quiet.Timezone == "" {
+			quiet.Timezone = models.DefaultTimezone
+		}
+		if _, err := time.LoadLocation(quiet.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid timezone: ") + quiet.Timezone})
+			return
+		}
+		preference.QuietHoursStart = quiet.Start
+		preference.QuietHoursEnd = quiet.End
+		preference.Timezone = quiet.Timezone
+	}
+
+	if err := initializers.DB.Save(&preference).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update notification preferences")})
+		return
+	}
+	c.JSON(http.StatusOK, toNotificationPreferencesResponse(preference))
+}
+
+func validCategory(category string) bool {
+	for _, known := range models.NotificationCategories {
+		if category == known {
+			return true
+		}
+	}
+	return false
+}
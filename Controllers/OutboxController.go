@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
+)
+
+// GetOutboxMessages lists outbox messages by ?status= (default: failed and dead),
+// newest first. The bodies of sensitive messages are redacted.
+func GetOutboxMessages(c *gin.Context) {
+	statuses := []string{models.OutboxStatusFailed, models.OutboxStatusDead}
+	if status := c.Query("status"); status != "" {
+		statuses = []string{status}
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid limit: must be between 1 and 500")})
+		return
+	}
+
+	var messages []models.OutboxMessage
+	if err := initializers.DB.Where("status IN ?", statuses).Order("updated_at DESC").Limit(limit).Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch outbox messages")})
+		return
+	}
+	for i := range messages {
+		messages[i] = messages[i].Redacted()
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// RetryOutboxMessage requeues a failed or dead message for immediate delivery
+func RetryOutboxMessage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid outbox message ID")})
+		return
+	}
+
+	var message models.OutboxMessage
+	if err := initializers.DB.First(&message, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Outbox message not found")})
+		return
+	}
+
+	if message.Status != models.OutboxStatusFailed && message.Status != models.OutboxStatusDead {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Only failed or dead messages can be retried")})
+		return
+	}
+	if message.Sensitive && message.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "The code in this message was discarded; request a new one")})
+		return
+	}
+
+	if err := notifications.RetryOutboxMessage(&message); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to retry outbox message")})
+		return
+	}
+	c.JSON(http.StatusOK, message.Redacted())
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ForgotPassword texts a reset code to the account's phone and emails a second,
+// separate code. The response is the same whether or not the number is
+// registered so it cannot be used to probe accounts.
+func ForgotPassword(c *gin.Context) {
+	type ForgotPasswordRequest struct {
+		Phone string `json:"phone" binding:"required"`
+	}
+
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "If the number is registered, a reset code has been sent"}
+
+	var user models.User
+	phone, err := helpers.NormalizePhone(req.Phone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid phone number: ") + req.Phone})
+		return
+	}
+	if err := initializers.DB.Where("phone = ?", phone).First(&user).Error; err != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Rate limited requests get the same answer too; a 429 would only ever
+	// be seen for registered numbers
+	code, err := issueOTP(user, models.OTPPurposePasswordReset)
+	if errors.Is(err, errOTPRateLimited) {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to create reset code")})
+		return
+	}
+
+	data := map[string]interface{}{
+		"Name":    user.Name,
+		"Code":    code,
+		"Minutes": int(helpers.OTPTTL().Minutes()),
+	}
+	if err := notifications.QueueCodeSMS(user.Locale, helpers.MessagePasswordResetSMS, data, []string{user.Phone}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to send reset code")})
+		return
+	}
+	// A code is emailed as well, in case the phone is out of reach. It is a
+	// different code so that using it proves nothing about the phone.
+	emailCode, err := issueOTP(user, models.OTPPurposePasswordResetEmail)
+	if err == nil {
+		data["Code"] = emailCode
+		err = notifications.QueueCodeEmail(user.Email, user.Locale, helpers.EmailTemplatePasswordReset, data)
+	}
+	if err != nil {
+		fmt.Println("Failed to queue password reset email:", err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword sets a new password using either code from ForgotPassword and
+// logs the account out of every device
+func ResetPassword(c *gin.Context) {
+	type ResetPasswordRequest struct {
+		Phone       string `json:"phone" binding:"required"`
+		Code        string `json:"code" binding:"required"`
+		NewPassword string `json:"newPassword" binding:"required"`
+	}
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	var user models.User
+	phone, err := helpers.NormalizePhone(req.Phone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid or expired code")})
+		return
+	}
+	if err := initializers.DB.Where("phone = ?", phone).First(&user).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid or expired code")})
+		return
+	}
+
+	usedSmsCode := true
+	if err := consumeOTP(initializers.DB, user, models.OTPPurposePasswordReset, req.Code); err != nil {
+		usedSmsCode = false
+		if err := consumeOTP(initializers.DB, user, models.OTPPurposePasswordResetEmail, req.Code); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid or expired code")})
+			return
+		}
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to process password")})
+		return
+	}
+
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"password":             string(hashedPassword),
+			"failed_logins":        0,
+			"last_failed_login_at": nil,
+			"locked_until":         nil,
+		}
+		// Receiving the SMS code proves the user holds the phone; the emailed one does not
+		if usedSmsCode && !user.PhoneVerified() {
+			updates["phone_verified_at"] = time.Now()
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		// The other code of the pair must not allow a second reset
+		if err := tx.Model(&models.OneTimeCode{}).
+			Where("user_id = ? AND purpose IN ? AND consumed_at IS NULL", user.ID, []string{models.OTPPurposePasswordReset, models.OTPPurposePasswordResetEmail}).
+			Update("consumed_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, user.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to reset password")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully. Please log in again."})
+}
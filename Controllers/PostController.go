@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
-	"github.com/group4/campus-connect-api/Helpers"
+	"strings"
+	"time"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	initializers "github.com/group4/campus-connect-api/Initializers"
@@ -13,13 +15,47 @@ import (
 // Response struct to control user fields in the response
 type PostResponse struct {
 	gorm.Model
-	Image       string       `json:"image"`
-	Title       string       `json:"title"`
-	Description string       `json:"description"`
-	UserID      uint         `json:"userID"`
-	User        UserResponse `json:"user"`
+	Image        string              `json:"image"` // the cover, i.e. the first image of the gallery
+	Images       []PostImageResponse `json:"images"`
+	Title        string              `json:"title"`
+	Description  string              `json:"description"`
+	UserID       uint                `json:"userID"`
+	User         UserResponse        `json:"user"`
+	Tags         []string            `json:"tags"`
+	CommentCount int64               `json:"commentCount"`
+	Status       string              `json:"status"`
+	PublishAt    *time.Time          `json:"publishAt"`
+	HiddenAt     *time.Time          `json:"hiddenAt,omitempty"`
+	ReactionSummary
 }
 
+// postRequest is the body of CreatePost. Image is the single image clients
+// sent before galleries; it becomes the cover.
+type postRequest struct {
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	UserID      uint               `json:"userID"`
+	Image       string             `json:"image"`
+	Images      []postImageRequest `json:"images"`
+	Status      string             `json:"status"`
+	PublishAt   *time.Time         `json:"publishAt"`
+}
+
+// postUpdateRequest is the body of UpdatePost. Title and Description are only
+// changed when present, so a request with just a status publishes or
+// schedules the post as it is.
+type postUpdateRequest struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	UserID      uint       `json:"userID"`
+	Image       string     `json:"image"`
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publishAt"`
+}
+
+// errPostChanged is returned when a post's status changed while it was being updated
+var errPostChanged = errors.New("post was changed")
+
 // UserResponse struct to exclude sensitive fields
 type UserResponse struct {
 	ID           uint   `json:"id"`
@@ -32,232 +68,369 @@ type UserResponse struct {
 
 func GetPosts(c *gin.Context) {
 	var posts []models.Post
-	if err := initializers.DB.Preload("User").Find(&posts).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+	if err := initializers.DB.Scopes(publishedPosts(c)).Preload("User").Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch posts")})
+		return
+	}
+
+	c.JSON(http.StatusOK, toPostResponses(posts, c.GetUint("userID")))
+}
+
+// GetMyDrafts lists the caller's drafts and scheduled posts, which no other
+// listing shows, most recently edited first
+func GetMyDrafts(c *gin.Context) {
+	var posts []models.Post
+	if err := initializers.DB.Preload("User").
+		Where("user_id = ? AND status <> ?", c.GetUint("userID"), models.PostStatusPublished).
+		Order("updated_at DESC").
+		Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch posts")})
 		return
 	}
 
-	// Convert to response struct to control fields
-	var postResponses []PostResponse
+	c.JSON(http.StatusOK, toPostResponses(posts, c.GetUint("userID")))
+}
+
+// applyPostStatus moves the post to status, writing a 400 and returning
+// ok=false when the move is not allowed. Scheduled posts need a publishAt in
+// the future, unless they keep the one they have; publishing stamps the
+// current time. It reports whether this publishes the post.
+func applyPostStatus(c *gin.Context, post *models.Post, status string, publishAt *time.Time) (published bool, ok bool) {
+	if !models.ValidPostStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid status: must be one of ") + strings.Join(models.PostStatuses, ", ")})
+		return false, false
+	}
+	if post.Status == models.PostStatusPublished && status != models.PostStatusPublished {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Published posts cannot be unpublished")})
+		return false, false
+	}
+
+	now := time.Now()
+	switch status {
+	case models.PostStatusDraft:
+		post.PublishAt = nil
+	case models.PostStatusScheduled:
+		if publishAt == nil && post.Status != models.PostStatusScheduled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Scheduled posts need a publishAt time")})
+			return false, false
+		}
+		if publishAt != nil {
+			if !publishAt.After(now) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "publishAt must be in the future")})
+				return false, false
+			}
+			post.PublishAt = publishAt
+		}
+	case models.PostStatusPublished:
+		if post.Status != models.PostStatusPublished {
+			post.PublishAt = &now
+			published = true
+		}
+	}
+	post.Status = status
+	return published, true
+}
+
+// toPostResponses converts posts with their preloaded authors to responses.
+// Images, tags, comment counts and reactions, including the viewer's own, are
+// read for all of them at once.
+func toPostResponses(posts []models.Post, viewerID uint) []PostResponse {
+	postIDs := make([]uint, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	galleries := postGalleries(postIDs)
+	tags := postTagNames(postIDs)
+	counts := commentCounts(postIDs)
+	reactions := reactionSummaries(postIDs, viewerID)
+
+	postResponses := []PostResponse{}
 	for _, post := range posts {
+		images := galleries[post.ID]
+		cover := ""
+		if len(images) > 0 {
+			cover = images[0].Path
+		} else {
+			images = []PostImageResponse{}
+		}
 		postResponses = append(postResponses, PostResponse{
-			Model:       post.Model,
-			Image:       post.Image,
-			Title:       post.Title,
-			Description: post.Description,
-			UserID:      post.UserID,
-			User: UserResponse{
-				ID:           post.User.ID,
-				Name:         post.User.Name,
-				ProfileImage: post.User.ProfileImage,
-				Role:         post.User.Role,
-				Course:       post.User.Course,
-				Year:         post.User.Year,
-			},
+			Model:           post.Model,
+			Image:           cover,
+			Images:          images,
+			Title:           post.Title,
+			Description:     post.Description,
+			UserID:          post.UserID,
+			User:            toUserResponse(post.User),
+			Tags:            tags[post.ID],
+			CommentCount:    counts[post.ID],
+			Status:          post.Status,
+			PublishAt:       post.PublishAt,
+			HiddenAt:        post.HiddenAt,
+			ReactionSummary: reactions[post.ID],
 		})
 	}
+	return postResponses
+}
 
-	c.JSON(http.StatusOK, postResponses)
+func toPostResponse(post models.Post, viewerID uint) PostResponse {
+	return toPostResponses([]models.Post{post}, viewerID)[0]
 }
 
 func CreatePost(c *gin.Context) {
-	var post models.Post
-	if err := c.ShouldBindJSON(&post); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+	var input postRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
 		return
 	}
 
-	// Validate UserID exists
-	var user models.User
-	if err := initializers.DB.First(&user, post.UserID).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UserID: User not found"})
+	// The author is always the authenticated caller, never the request body
+	user := c.MustGet("user").(models.User)
+	post := models.Post{Title: input.Title, Description: input.Description, UserID: user.ID}
+
+	status := input.Status
+	if status == "" {
+		status = models.PostStatusPublished
+	}
+	if _, ok := applyPostStatus(c, &post, status, input.PublishAt); !ok {
 		return
 	}
 
-	// Handle image upload if provided
-	if post.Image != "" {
-		// Sanitize title for filename
-		sanitizedTitle := helpers.SanitizeFilename(post.Title)
-		filename := "post-" + sanitizedTitle
-		imagePath, err := helpers.SaveImage(post.Image, filename)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image: " + err.Error()})
-			return
-		}
-		post.Image = imagePath // Store relative path in DB
+	uploads := input.Images
+	if input.Image != "" {
+		uploads = append([]postImageRequest{{Image: input.Image}}, uploads...)
 	}
+	if !validateImageUploads(c, uploads, 0) {
+		return
+	}
+	images, err := savePostImages(uploads, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to save image: ") + err.Error()})
+		return
+	}
+	post.Images = images // created along with the post
 
-	if err := initializers.DB.Create(&post).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post: " + err.Error()})
+	var mentioned []uint
+	if err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		var err error
+		mentioned, err = syncPostText(tx, post)
+		return err
+	}); err != nil {
+		discardImageFiles(images)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to create post: ") + err.Error()})
 		return
 	}
+	// Drafts and scheduled posts tell their mentions once they are published
+	if post.Status == models.PostStatusPublished {
+		notifyMentions(post, user, mentioned)
+	}
 
 	// Return post with user details
-	postResponse := PostResponse{
-		Model:       post.Model,
-		Image:       post.Image,
-		Title:       post.Title,
-		Description: post.Description,
-		UserID:      post.UserID,
-		User: UserResponse{
-			ID:           user.ID,
-			Name:         user.Name,
-			ProfileImage: user.ProfileImage,
-			Role:         user.Role,
-			Course:       user.Course,
-			Year:         user.Year,
-		},
-	}
-
-	c.JSON(http.StatusCreated, postResponse)
+	post.User = user
+	c.JSON(http.StatusCreated, toPostResponse(post, c.GetUint("userID")))
 }
 
 func GetPostByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid post ID")})
 		return
 	}
 
 	var post models.Post
-	if err := initializers.DB.Preload("User").First(&post, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+	if err := initializers.DB.Scopes(visiblePosts(c)).Preload("User").First(&post, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Post not found")})
 		return
 	}
 
-	// Convert to response struct
-	postResponse := PostResponse{
-		Model:       post.Model,
-		Image:       post.Image,
-		Title:       post.Title,
-		Description: post.Description,
-		UserID:      post.UserID,
-		User: UserResponse{
-			ID:           post.User.ID,
-			Name:         post.User.Name,
-			ProfileImage: post.User.ProfileImage,
-			Role:         post.User.Role,
-			Course:       post.User.Course,
-			Year:         post.User.Year,
-		},
-	}
-
-	c.JSON(http.StatusOK, postResponse)
+	c.JSON(http.StatusOK, toPostResponse(post, c.GetUint("userID")))
 }
 
 func UpdatePost(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid post ID")})
 		return
 	}
 
 	var post models.Post
 	if err := initializers.DB.First(&post, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Post not found")})
 		return
 	}
 
-	var updatedPost models.Post
+	if !canModifyPost(c, post) {
+		forbid(c, "only the author, a moderator or an admin can edit this post")
+		return
+	}
+
+	var updatedPost postUpdateRequest
 	if err := c.ShouldBindJSON(&updatedPost); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	// Authorship cannot be reassigned through an update
+	if updatedPost.UserID != 0 && updatedPost.UserID != post.UserID {
+		forbid(c, "post author cannot be changed")
 		return
 	}
 
-	// Validate UserID if provided
-	if updatedPost.UserID != 0 {
-		var user models.User
-		if err := initializers.DB.First(&user, updatedPost.UserID).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid UserID: User not found"})
+	// A single image replaces the cover; the rest of the gallery is managed
+	// through the image endpoints
+	var cover []models.PostImage
+	if updatedPost.Image != "" {
+		if cover, err = savePostImages([]postImageRequest{{Image: updatedPost.Image}}, 0); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to save image: ") + err.Error()})
 			return
 		}
-		post.UserID = updatedPost.UserID
-	}
-
-	// Handle image update if provided
-	if updatedPost.Image != "" && updatedPost.Image != post.Image {
-		// Delete old image if it exists
-		if post.Image != "" {
-			if err := helpers.DeleteImage(post.Image); err != nil {
-				// Log warning but proceed
-				c.JSON(http.StatusOK, gin.H{
-					"warning": "Failed to delete old image: " + err.Error(),
-					"post":    post,
-				})
+	}
+
+	// An empty status keeps the current one
+	previousStatus := post.Status
+	status := updatedPost.Status
+	if status == "" {
+		status = post.Status
+	}
+	published, ok := applyPostStatus(c, &post, status, updatedPost.PublishAt)
+	if !ok {
+		discardImageFiles(cover)
+		return
+	}
+
+	// Update the fields sent; tags and mentions follow the text
+	textChanged := false
+	if updatedPost.Title != nil && *updatedPost.Title != post.Title {
+		post.Title = *updatedPost.Title
+		textChanged = true
+	}
+	if updatedPost.Description != nil && *updatedPost.Description != post.Description {
+		post.Description = *updatedPost.Description
+		textChanged = true
+	}
+
+	var mentioned []uint
+	var replaced []string
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// The status guard keeps a post the scheduler has just published from going back to scheduled
+		result := tx.Model(&post).
+			Where("status = ?", previousStatus).
+			Select("title", "description", "status", "publish_at").
+			Updates(&post)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPostChanged
+		}
+		if len(cover) > 0 {
+			images, err := lockGallery(tx, post.ID)
+			if err != nil {
+				return err
+			}
+			if len(images) > 0 {
+				replaced = append(replaced, images[0].Path)
+				if err := tx.Model(&images[0]).Update("path", cover[0].Path).Error; err != nil {
+					return err
+				}
+			} else {
+				cover[0].PostID = post.ID
+				if err := tx.Create(&cover[0]).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if textChanged {
+			var err error
+			if mentioned, err = syncPostText(tx, post); err != nil {
+				return err
 			}
 		}
-		// Save new image with sanitized title
-		sanitizedTitle := helpers.SanitizeFilename(updatedPost.Title)
-		filename := "post-" + sanitizedTitle
-		imagePath, err := helpers.SaveImage(updatedPost.Image, filename)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image: " + err.Error()})
+		// Publishing tells everyone mentioned so far, not just the new mentions
+		if published {
+			return tx.Model(&models.PostMention{}).Where("post_id = ?", post.ID).Pluck("user_id", &mentioned).Error
+		}
+		return nil
+	})
+	if err != nil {
+		discardImageFiles(cover)
+		if errors.Is(err, errPostChanged) {
+			c.JSON(http.StatusConflict, gin.H{"error": localize(c, "Post was changed in the meantime, try again")})
 			return
 		}
-		post.Image = imagePath
-	}
-
-	// Update other fields
-	post.Title = updatedPost.Title
-	post.Description = updatedPost.Description
-
-	if err := initializers.DB.Save(&post).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update post"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update post")})
 		return
 	}
+	deleteImageFiles(replaced)
 
 	// Fetch updated user details
-	var user models.User
-	initializers.DB.First(&user, post.UserID)
+	initializers.DB.First(&post.User, post.UserID)
+	if post.Status == models.PostStatusPublished {
+		notifyMentions(post, post.User, mentioned)
+	}
 
 	// Return updated post with user details
-	postResponse := PostResponse{
-		Model:       post.Model,
-		Image:       post.Image,
-		Title:       post.Title,
-		Description: post.Description,
-		UserID:      post.UserID,
-		User: UserResponse{
-			ID:           user.ID,
-			Name:         user.Name,
-			ProfileImage: user.ProfileImage,
-			Role:         user.Role,
-			Course:       user.Course,
-			Year:         user.Year,
-		},
-	}
-
-	c.JSON(http.StatusOK, postResponse)
+	c.JSON(http.StatusOK, toPostResponse(post, c.GetUint("userID")))
 }
 
 func DeletePost(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid post ID")})
 		return
 	}
 
 	var post models.Post
 	if err := initializers.DB.First(&post, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Post not found")})
 		return
 	}
 
-	// Delete image file if it exists
-	if post.Image != "" {
-		if err := helpers.DeleteImage(post.Image); err != nil {
-			// Log the error but proceed with deletion
-			c.JSON(http.StatusOK, gin.H{
-				"message": "Post deleted successfully",
-				"warning": "Failed to delete associated image: " + err.Error(),
-			})
-			return
-		}
+	if !canModifyPost(c, post) {
+		forbid(c, "only the author, a moderator or an admin can delete this post")
+		return
 	}
 
-	if err := initializers.DB.Delete(&post).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete post"})
+	var imagePaths []string
+	if err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		imagePaths, err = deletePostRecords(tx, post)
+		return err
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to delete post")})
 		return
 	}
+	deleteImageFiles(imagePaths)
 	c.JSON(http.StatusOK, gin.H{"message": "Post deleted successfully"})
+}
+
+// deletePostRecords deletes a post with its images, comments, reactions, tags
+// and mentions. It returns the image paths, whose files the caller deletes
+// with deleteImageFiles once the transaction has committed.
+func deletePostRecords(tx *gorm.DB, post models.Post) ([]string, error) {
+	var imagePaths []string
+	if err := tx.Model(&models.PostImage{}).Where("post_id = ?", post.ID).Pluck("path", &imagePaths).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("post_id = ?", post.ID).Delete(&models.PostImage{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.Comment{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("post_id = ?", post.ID).Delete(&models.PostReaction{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostReactionCount{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostTag{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostMention{}).Error; err != nil {
+		return nil, err
+	}
+	return imagePaths, tx.Delete(&post).Error
 }
\ No newline at end of file
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+// setupPostUpdate uses a test database holding a draft by its author and
+// returns a router serving UpdatePost as that author
+func setupPostUpdate(t *testing.T) (*gin.Engine, models.Post) {
+	db := useTestDB(t,
+		&models.User{}, &models.Post{}, &models.PostImage{}, &models.Comment{},
+		&models.PostReaction{}, &models.PostReactionCount{}, &models.Tag{}, &models.PostTag{}, &models.PostMention{},
+	)
+
+	author := models.User{Name: "Author", Email: "author@example.com", Phone: "+255700000001", Role: models.RoleStudent}
+	if err := db.Create(&author).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	post := models.Post{Title: "Club fair", Description: "Join us at the main hall", UserID: author.ID, Status: models.PostStatusDraft}
+	if err := db.Create(&post).Error; err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/api/posts/:id/update", func(c *gin.Context) {
+		c.Set("userID", author.ID)
+		c.Set("role", author.Role)
+		c.Set("user", author)
+	}, UpdatePost)
+	return router, post
+}
+
+func putPost(router *gin.Engine, postID uint, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, "/api/posts/"+strconv.Itoa(int(postID))+"/update", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func reloadPost(t *testing.T, id uint) models.Post {
+	var post models.Post
+	if err := initializers.DB.First(&post, id).Error; err != nil {
+		t.Fatalf("reload post: %v", err)
+	}
+	return post
+}
+
+func TestUpdatePostStatusOnlyKeepsText(t *testing.T) {
+	router, post := setupPostUpdate(t)
+
+	w := putPost(router, post.ID, `{"status":"published"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body)
+	}
+
+	got := reloadPost(t, post.ID)
+	if got.Status != models.PostStatusPublished || got.PublishAt == nil {
+		t.Errorf("post is %q with publishAt %v, want published now", got.Status, got.PublishAt)
+	}
+	if got.Title != post.Title || got.Description != post.Description {
+		t.Errorf("post text is %q / %q, want %q / %q", got.Title, got.Description, post.Title, post.Description)
+	}
+}
+
+func TestUpdatePostScheduleOnlyKeepsText(t *testing.T) {
+	router, post := setupPostUpdate(t)
+
+	publishAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	w := putPost(router, post.ID, `{"status":"scheduled","publishAt":"`+publishAt+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body)
+	}
+
+	got := reloadPost(t, post.ID)
+	if got.Status != models.PostStatusScheduled || got.PublishAt == nil {
+		t.Errorf("post is %q with publishAt %v, want scheduled", got.Status, got.PublishAt)
+	}
+	if got.Title != post.Title || got.Description != post.Description {
+		t.Errorf("post text is %q / %q, want %q / %q", got.Title, got.Description, post.Title, post.Description)
+	}
+}
+
+func TestUpdatePostChangesOnlyFieldsSent(t *testing.T) {
+	router, post := setupPostUpdate(t)
+
+	w := putPost(router, post.ID, `{"title":"Club fair moved"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body)
+	}
+
+	got := reloadPost(t, post.ID)
+	if got.Title != "Club fair moved" {
+		t.Errorf("title is %q, want the new one", got.Title)
+	}
+	if got.Description != post.Description || got.Status != models.PostStatusDraft {
+		t.Errorf("post is %q / %q, want the description and draft status kept", got.Description, got.Status)
+	}
+}
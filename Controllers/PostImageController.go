@@ -0,0 +1,375 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxCaptionLength = 300
+
+// errGalleryFull is returned when an image is added to a post that already has MaxPostImages
+var errGalleryFull = errors.New("gallery is full")
+
+type PostImageResponse struct {
+	ID       uint   `json:"id"`
+	Path     string `json:"path"`
+	Caption  string `json:"caption"`
+	Position int    `json:"position"`
+}
+
+// postImageRequest is one uploaded image: base64 data and an optional caption
+type postImageRequest struct {
+	Image   string `json:"image"`
+	Caption string `json:"caption"`
+}
+
+type imageOrderRequest struct {
+	ImageIDs []uint `json:"imageIDs"`
+}
+
+func toPostImageResponse(image models.PostImage) PostImageResponse {
+	return PostImageResponse{
+		ID:       image.ID,
+		Path:     image.Path,
+		Caption:  image.Caption,
+		Position: image.Position,
+	}
+}
+
+func toPostImageResponses(images []models.PostImage) []PostImageResponse {
+	responses := []PostImageResponse{}
+	for _, image := range images {
+		responses = append(responses, toPostImageResponse(image))
+	}
+	return responses
+}
+
+// postGalleries reads the images of each post in gallery order
+func postGalleries(postIDs []uint) map[uint][]PostImageResponse {
+	galleries := map[uint][]PostImageResponse{}
+	if len(postIDs) == 0 {
+		return galleries
+	}
+
+	var images []models.PostImage
+	initializers.DB.Where("post_id IN ?", postIDs).Order("post_id, position").Find(&images)
+	for _, image := range images {
+		galleries[image.PostID] = append(galleries[image.PostID], toPostImageResponse(image))
+	}
+	return galleries
+}
+
+// validateImageUploads trims captions and writes a 400 if an image is missing,
+// a caption is too long or the gallery would hold more than MaxPostImages
+func validateImageUploads(c *gin.Context, uploads []postImageRequest, existing int) bool {
+	if existing+len(uploads) > models.MaxPostImages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Too many images: max ") + strconv.Itoa(models.MaxPostImages)})
+		return false
+	}
+	for i := range uploads {
+		if uploads[i].Image == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Image cannot be empty")})
+			return false
+		}
+		caption, ok := validateCaption(c, uploads[i].Caption)
+		if !ok {
+			return false
+		}
+		uploads[i].Caption = caption
+	}
+	return true
+}
+
+// validateCaption trims the caption and writes a 400 if it is too long
+func validateCaption(c *gin.Context, caption string) (string, bool) {
+	caption = strings.TrimSpace(caption)
+	if utf8.RuneCountInString(caption) > maxCaptionLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Caption is too long: max ") + strconv.Itoa(maxCaptionLength)})
+		return "", false
+	}
+	return caption, true
+}
+
+// savePostImages writes the uploads to ./Images under random names, so images
+// never overwrite each other, and numbers them from position from. If one
+// upload fails, the files already written are removed again.
+func savePostImages(uploads []postImageRequest, from int) ([]models.PostImage, error) {
+	var images []models.PostImage
+	for i, upload := range uploads {
+		filename, err := helpers.UniqueFilename("post")
+		path := ""
+		if err == nil {
+			path, err = helpers.SaveImage(upload.Image, filename)
+		}
+		if err != nil {
+			discardImageFiles(images)
+			return nil, err
+		}
+		images = append(images, models.PostImage{Path: path, Caption: upload.Caption, Position: from + i})
+	}
+	return images, nil
+}
+
+// discardImageFiles removes files written for images that were never stored
+func discardImageFiles(images []models.PostImage) {
+	for _, image := range images {
+		if err := helpers.DeleteImage(image.Path); err != nil {
+			log.Println("Failed to discard post image:", err)
+		}
+	}
+}
+
+// deleteImageFiles removes the files of deleted images. A file still used by
+// another image is kept: posts from before galleries could share one file.
+// Failures are only logged, since the images are already gone from their posts.
+func deleteImageFiles(paths []string) {
+	for _, path := range paths {
+		var count int64
+		initializers.DB.Model(&models.PostImage{}).Where("path = ?", path).Count(&count)
+		if count > 0 {
+			continue
+		}
+		if err := helpers.DeleteImage(path); err != nil {
+			log.Println("Failed to delete post image:", err)
+		}
+	}
+}
+
+// lockGallery locks the post so concurrent gallery changes apply one at a time,
+// and returns its images in order
+func lockGallery(tx *gorm.DB, postID uint) ([]models.PostImage, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Post{}, postID).Error; err != nil {
+		return nil, err
+	}
+	var images []models.PostImage
+	err := tx.Where("post_id = ?", postID).Order("position").Find(&images).Error
+	return images, err
+}
+
+// renumberGallery stores each image's index in images as its position
+func renumberGallery(tx *gorm.DB, images []models.PostImage) error {
+	for i := range images {
+		if images[i].Position == i {
+			continue
+		}
+		images[i].Position = i
+		if err := tx.Model(&images[i]).Update("position", i).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findEditablePost loads the post in the :id parameter and checks the caller may
+// edit it, writing a 400, 403 or 404 on failure
+func findEditablePost(c *gin.Context) (models.Post, bool) {
+	post, ok := findPostByParam(c)
+	if !ok {
+		return post, false
+	}
+	if !canModifyPost(c, post) {
+		forbid(c, "only the author, a moderator or an admin can edit this post")
+		return post, false
+	}
+	return post, true
+}
+
+// findPostImage loads the image in the :imageId parameter on the post, writing a 400 or 404 on failure
+func findPostImage(c *gin.Context, post models.Post) (models.PostImage, bool) {
+	var image models.PostImage
+	id, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid image ID")})
+		return image, false
+	}
+	if err := initializers.DB.Where("post_id = ?", post.ID).First(&image, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Image not found")})
+		return image, false
+	}
+	return image, true
+}
+
+// AddPostImage appends an image to the end of a post's gallery
+func AddPostImage(c *gin.Context) {
+	post, ok := findEditablePost(c)
+	if !ok {
+		return
+	}
+
+	var input postImageRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	uploads := []postImageRequest{input}
+	if !validateImageUploads(c, uploads, 0) {
+		return
+	}
+
+	saved, err := savePostImages(uploads, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to save image: ") + err.Error()})
+		return
+	}
+	image := saved[0]
+	image.PostID = post.ID
+
+	var gallery []models.PostImage
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		images, err := lockGallery(tx, post.ID)
+		if err != nil {
+			return err
+		}
+		if len(images) >= models.MaxPostImages {
+			return errGalleryFull
+		}
+		image.Position = len(images)
+		if err := tx.Create(&image).Error; err != nil {
+			return err
+		}
+		gallery = append(images, image)
+		return nil
+	})
+	if err != nil {
+		discardImageFiles(saved)
+		if errors.Is(err, errGalleryFull) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Too many images: max ") + strconv.Itoa(models.MaxPostImages)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to add image")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"images": toPostImageResponses(gallery)})
+}
+
+// UpdatePostImage changes an image's caption
+func UpdatePostImage(c *gin.Context) {
+	post, ok := findEditablePost(c)
+	if !ok {
+		return
+	}
+	image, ok := findPostImage(c, post)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Caption string `json:"caption"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	caption, ok := validateCaption(c, input.Caption)
+	if !ok {
+		return
+	}
+
+	if err := initializers.DB.Model(&image).Update("caption", caption).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update image")})
+		return
+	}
+	image.Caption = caption
+	c.JSON(http.StatusOK, toPostImageResponse(image))
+}
+
+// DeletePostImage removes an image from a post's gallery and closes the gap it leaves
+func DeletePostImage(c *gin.Context) {
+	post, ok := findEditablePost(c)
+	if !ok {
+		return
+	}
+	image, ok := findPostImage(c, post)
+	if !ok {
+		return
+	}
+
+	var gallery []models.PostImage
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		images, err := lockGallery(tx, post.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&models.PostImage{}, image.ID).Error; err != nil {
+			return err
+		}
+		for _, remaining := range images {
+			if remaining.ID != image.ID {
+				gallery = append(gallery, remaining)
+			}
+		}
+		return renumberGallery(tx, gallery)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to delete image")})
+		return
+	}
+	deleteImageFiles([]string{image.Path})
+
+	c.JSON(http.StatusOK, gin.H{"images": toPostImageResponses(gallery)})
+}
+
+// ReorderPostImages puts a post's images in the order of the image IDs given,
+// which must list every image of the post exactly once
+func ReorderPostImages(c *gin.Context) {
+	post, ok := findEditablePost(c)
+	if !ok {
+		return
+	}
+
+	var input imageOrderRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	var gallery []models.PostImage
+	invalidOrder := false
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		images, err := lockGallery(tx, post.ID)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[uint]models.PostImage, len(images))
+		for _, image := range images {
+			byID[image.ID] = image
+		}
+		if len(input.ImageIDs) != len(images) {
+			invalidOrder = true
+			return nil
+		}
+		for _, id := range input.ImageIDs {
+			image, ok := byID[id]
+			if !ok {
+				invalidOrder = true
+				return nil
+			}
+			delete(byID, id)
+			gallery = append(gallery, image)
+		}
+		return renumberGallery(tx, gallery)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to reorder images")})
+		return
+	}
+	if invalidOrder {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid order: list each image once")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"images": toPostImageResponses(gallery)})
+}
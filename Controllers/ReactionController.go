@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReactionSummary is the aggregated reactions on a post as seen by one viewer
+type ReactionSummary struct {
+	Reactions      map[string]int64 `json:"reactions"`
+	ReactionCount  int64            `json:"reactionCount"`
+	ViewerReaction *string          `json:"viewerReaction"`
+}
+
+type reactionRequest struct {
+	Type string `json:"type"`
+}
+
+// reactionSummaries reads the reaction totals of the posts, and the viewer's own
+// reaction when viewerID is set, in at most two queries
+func reactionSummaries(postIDs []uint, viewerID uint) map[uint]ReactionSummary {
+	summaries := map[uint]ReactionSummary{}
+	for _, postID := range postIDs {
+		reactions := map[string]int64{}
+		for _, t := range models.ReactionTypes {
+			reactions[t] = 0
+		}
+		summaries[postID] = ReactionSummary{Reactions: reactions}
+	}
+	if len(postIDs) == 0 {
+		return summaries
+	}
+
+	var counts []models.PostReactionCount
+	initializers.DB.Where("post_id IN ? AND count > 0", postIDs).Find(&counts)
+	for _, count := range counts {
+		summary := summaries[count.PostID]
+		summary.Reactions[count.Type] = count.Count
+		summary.ReactionCount += count.Count
+		summaries[count.PostID] = summary
+	}
+
+	if viewerID != 0 {
+		var own []models.PostReaction
+		initializers.DB.Select("post_id", "type").Where("user_id = ? AND post_id IN ?", viewerID, postIDs).Find(&own)
+		for _, reaction := range own {
+			summary := summaries[reaction.PostID]
+			reactionType := reaction.Type
+			summary.ViewerReaction = &reactionType
+			summaries[reaction.PostID] = summary
+		}
+	}
+	return summaries
+}
+
+// adjustReactionCount adds delta to the post's total for the reaction type
+func adjustReactionCount(tx *gorm.DB, postID uint, reactionType string, delta int64) error {
+	if delta > 0 {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "post_id"}, {Name: "type"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("post_reaction_counts.count + ?", delta)}),
+		}).Create(&models.PostReactionCount{PostID: postID, Type: reactionType, Count: delta}).Error
+	}
+	return tx.Model(&models.PostReactionCount{}).
+		Where("post_id = ? AND type = ?", postID, reactionType).
+		Update("count", gorm.Expr("GREATEST(count + ?, 0)", delta)).Error
+}
+
+// ToggleReaction reacts to a post. Sending the caller's current reaction
+// again removes it; sending a different one replaces it.
+func ToggleReaction(c *gin.Context) {
+	post, ok := findPostByParam(c)
+	if !ok {
+		return
+	}
+
+	var input reactionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	reactionType := strings.ToLower(strings.TrimSpace(input.Type))
+	if !models.ValidReaction(reactionType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid reaction: must be one of ") + strings.Join(models.ReactionTypes, ", ")})
+		return
+	}
+
+	userID := c.GetUint("userID")
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.PostReaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("post_id = ? AND user_id = ?", post.ID, userID).
+			Limit(1).
+			Find(&existing).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case existing.ID == 0:
+			// The lock above has no row to hold yet, so a concurrent first
+			// reaction may insert before us. It wins; only count our own insert.
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "post_id"}, {Name: "user_id"}},
+				DoNothing: true,
+			}).Create(&models.PostReaction{PostID: post.ID, UserID: userID, Type: reactionType})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			return adjustReactionCount(tx, post.ID, reactionType, 1)
+		case existing.Type == reactionType:
+			if err := tx.Unscoped().Delete(&existing).Error; err != nil {
+				return err
+			}
+			return adjustReactionCount(tx, post.ID, reactionType, -1)
+		default:
+			if err := adjustReactionCount(tx, post.ID, existing.Type, -1); err != nil {
+				return err
+			}
+			if err := tx.Model(&existing).Update("type", reactionType).Error; err != nil {
+				return err
+			}
+			return adjustReactionCount(tx, post.ID, reactionType, 1)
+		}
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to react to post")})
+		return
+	}
+
+	c.JSON(http.StatusOK, reactionSummaries([]uint{post.ID}, userID)[post.ID])
+}
+
+// RemoveReaction removes the caller's reaction to a post, if any
+func RemoveReaction(c *gin.Context) {
+	post, ok := findPostByParam(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("userID")
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.PostReaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("post_id = ? AND user_id = ?", post.ID, userID).
+			Limit(1).
+			Find(&existing).Error; err != nil {
+			return err
+		}
+		if existing.ID == 0 {
+			return nil
+		}
+		if err := tx.Unscoped().Delete(&existing).Error; err != nil {
+			return err
+		}
+		return adjustReactionCount(tx, post.ID, existing.Type, -1)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to remove reaction")})
+		return
+	}
+
+	c.JSON(http.StatusOK, reactionSummaries([]uint{post.ID}, userID)[post.ID])
+}
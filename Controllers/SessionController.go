@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
+)
+
+// TokenPair is returned whenever a session is started or refreshed
+type TokenPair struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	TokenType    string    `json:"tokenType"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// SessionResponse describes a logged-in device without exposing token hashes
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Current    bool      `json:"current"`
+}
+
+// startSession creates a new session for the user and issues its first token pair
+func startSession(c *gin.Context, user models.User) (TokenPair, error) {
+	now := time.Now()
+	session := models.Session{
+		UserID:     user.ID,
+		UserAgent:  c.Request.UserAgent(),
+		IPAddress:  c.ClientIP(),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(helpers.RefreshTokenTTL()),
+	}
+
+	var tokens TokenPair
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		var err error
+		tokens, err = issueTokens(tx, user, session)
+		return err
+	})
+	return tokens, err
+}
+
+// issueTokens stores a fresh refresh token for the session and signs a matching access token
+func issueTokens(tx *gorm.DB, user models.User, session models.Session) (TokenPair, error) {
+	refreshToken, err := helpers.GenerateRefreshToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if err := tx.Create(&models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: helpers.HashToken(refreshToken),
+	}).Error; err != nil {
+		return TokenPair{}, err
+	}
+
+	accessToken, expiresAt, err := helpers.GenerateAccessToken(user.ID, user.Role, session.ID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// revokeSession marks a single session as revoked
+func revokeSession(db *gorm.DB, sessionID uint) error {
+	return db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// revokeUserSessions revokes every active session belonging to a user
+func revokeUserSessions(db *gorm.DB, userID uint) error {
+	return db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// errSessionRevoked is returned when a session is revoked or expires while it is being refreshed
+var errSessionRevoked = errors.New("session revoked")
+
+// RefreshSession rotates a refresh token. Presenting an already-used token is
+// treated as theft and revokes the whole session.
+func RefreshSession(c *gin.Context) {
+	type RefreshRequest struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	var stored models.RefreshToken
+	if err := initializers.DB.Preload("Session").Where("token_hash = ?", helpers.HashToken(req.RefreshToken)).First(&stored).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid refresh token")})
+		return
+	}
+
+	if !stored.Session.Active() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Session has expired or been revoked")})
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, stored.Session.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid refresh token")})
+		return
+	}
+
+	var tokens TokenPair
+	reused := false
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Only the first request to present this token may consume it
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", stored.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return nil
+		}
+
+		// Only touch the session if it is still live, so a logout or revoke
+		// that committed after it was loaded is not undone
+		session := stored.Session
+		session.LastUsedAt = now
+		session.ExpiresAt = now.Add(helpers.RefreshTokenTTL())
+		result = tx.Model(&models.Session{}).
+			Where("id = ? AND revoked_at IS NULL AND expires_at > ?", session.ID, now).
+			Updates(map[string]interface{}{"last_used_at": session.LastUsedAt, "expires_at": session.ExpiresAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSessionRevoked
+		}
+
+		var err error
+		tokens, err = issueTokens(tx, user, session)
+		return err
+	})
+	if errors.Is(err, errSessionRevoked) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Session has expired or been revoked")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to refresh session: ") + err.Error()})
+		return
+	}
+
+	if reused {
+		if err := revokeSession(initializers.DB, stored.SessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to revoke session")})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Refresh token reuse detected; session revoked")})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func Logout(c *gin.Context) {
+	if err := revokeSession(initializers.DB, c.GetUint("sessionID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to log out")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func GetSessions(c *gin.Context) {
+	var sessions []models.Session
+	if err := initializers.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", c.GetUint("userID"), time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch sessions")})
+		return
+	}
+
+	currentID := c.GetUint("sessionID")
+	sessionResponses := []SessionResponse{}
+	for _, session := range sessions {
+		sessionResponses = append(sessionResponses, SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, sessionResponses)
+}
+
+func RevokeSession(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid session ID")})
+		return
+	}
+
+	var session models.Session
+	if err := initializers.DB.Where("user_id = ?", c.GetUint("userID")).First(&session, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Session not found")})
+		return
+	}
+
+	if err := revokeSession(initializers.DB, session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to revoke session")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
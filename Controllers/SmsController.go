@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
+	"gorm.io/gorm"
+)
+
+// ReceiveSmsDeliveryReport is the webhook SMS providers post delivery reports
+// to. The body is one report or an array of them. Requests must carry
+// SMS_WEBHOOK_SECRET in the X-Webhook-Secret header; while it is unset or a
+// placeholder, every request is refused.
+func ReceiveSmsDeliveryReport(c *gin.Context) {
+	secret := helpers.SmsWebhookSecret()
+	if secret == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": localize(c, "SMS webhook is not configured")})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader(helpers.SmsWebhookSecretHeader)), []byte(secret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid webhook secret")})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	var reports []helpers.SmsDeliveryReport
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &reports)
+	} else {
+		var report helpers.SmsDeliveryReport
+		err = json.Unmarshal(trimmed, &report)
+		reports = append(reports, report)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	// Unknown messages are answered with 404 so the provider retries; a report
+	// can arrive before the send that produced it has been logged
+	updated := 0
+	for _, report := range reports {
+		_, err := notifications.ApplyDeliveryReport(report)
+		switch {
+		case errors.Is(err, notifications.ErrUnknownSmsStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid status: ") + report.Status})
+			return
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "SMS message not found"), "updated": updated})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to record delivery report")})
+			return
+		}
+		updated++
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// GetSmsMessages lists the SMS log, newest first, filtered by ?status= and ?recipient=
+func GetSmsMessages(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid limit: must be between 1 and 500")})
+		return
+	}
+
+	query := initializers.DB.Order("created_at DESC").Limit(limit)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if recipient := strings.TrimSpace(c.Query("recipient")); recipient != "" {
+		query = query.Where("recipient = ?", recipient)
+	}
+
+	var messages []models.SmsMessage
+	if err := query.Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch SMS messages")})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
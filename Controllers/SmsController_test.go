@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+const testWebhookSecret = "test-webhook-secret"
+
+// setupSmsWebhook uses a test database holding one sent message and returns a
+// router serving the delivery report webhook
+func setupSmsWebhook(t *testing.T) (*gin.Engine, models.SmsMessage) {
+	db := useTestDB(t, &models.SmsMessage{})
+
+	message := models.SmsMessage{
+		Provider:          "huduma",
+		ProviderMessageID: "msg-1",
+		Recipient:         "+255700000001",
+		Body:              "hello",
+		Status:            models.SmsStatusSent,
+	}
+	if err := db.Create(&message).Error; err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+
+	t.Setenv("SMS_WEBHOOK_SECRET", testWebhookSecret)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/webhooks/sms/delivery", ReceiveSmsDeliveryReport)
+	return router, message
+}
+
+func postDeliveryReport(router *gin.Engine, secret string, report helpers.SmsDeliveryReport) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(report)
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks/sms/delivery", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(helpers.SmsWebhookSecretHeader, secret)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func reloadSmsMessage(t *testing.T, id uint) models.SmsMessage {
+	var message models.SmsMessage
+	if err := initializers.DB.First(&message, id).Error; err != nil {
+		t.Fatalf("reload message: %v", err)
+	}
+	return message
+}
+
+func TestReceiveSmsDeliveryReportUpdatesStatus(t *testing.T) {
+	router, message := setupSmsWebhook(t)
+
+	w := postDeliveryReport(router, testWebhookSecret, helpers.SmsDeliveryReport{
+		Provider:  "huduma",
+		MessageID: "msg-1",
+		Status:    "DELIVRD",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body)
+	}
+
+	got := reloadSmsMessage(t, message.ID)
+	if got.Status != models.SmsStatusDelivered {
+		t.Errorf("message status is %q, want %q", got.Status, models.SmsStatusDelivered)
+	}
+	if got.DeliveredAt == nil {
+		t.Error("DeliveredAt was not set")
+	}
+
+	// A late "sent" report must not step back from delivered
+	w = postDeliveryReport(router, testWebhookSecret, helpers.SmsDeliveryReport{MessageID: "msg-1", Status: "sent"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d for the late report, want 200: %s", w.Code, w.Body)
+	}
+	if got := reloadSmsMessage(t, message.ID); got.Status != models.SmsStatusDelivered {
+		t.Errorf("message status is %q after a late sent report, want %q", got.Status, models.SmsStatusDelivered)
+	}
+}
+
+func TestReceiveSmsDeliveryReportFailure(t *testing.T) {
+	router, message := setupSmsWebhook(t)
+
+	w := postDeliveryReport(router, testWebhookSecret, helpers.SmsDeliveryReport{
+		MessageID: "msg-1",
+		Status:    "failed",
+		Error:     "absent subscriber",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body)
+	}
+
+	got := reloadSmsMessage(t, message.ID)
+	if got.Status != models.SmsStatusFailed || got.Error != "absent subscriber" {
+		t.Errorf("message is %q with error %q, want failed with the reported error", got.Status, got.Error)
+	}
+}
+
+func TestReceiveSmsDeliveryReportRejectsBadSecrets(t *testing.T) {
+	router, message := setupSmsWebhook(t)
+	report := helpers.SmsDeliveryReport{MessageID: "msg-1", Status: "delivered"}
+
+	if w := postDeliveryReport(router, "wrong-secret", report); w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d for a wrong secret, want 401", w.Code)
+	}
+
+	for _, placeholder := range []string{"", "change-me", "change-me-sms-webhook-secret"} {
+		t.Setenv("SMS_WEBHOOK_SECRET", placeholder)
+		if w := postDeliveryReport(router, placeholder, report); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("got status %d with SMS_WEBHOOK_SECRET=%q, want 503", w.Code, placeholder)
+		}
+	}
+
+	if got := reloadSmsMessage(t, message.ID); got.Status != models.SmsStatusSent {
+		t.Errorf("message status is %q after rejected reports, want %q", got.Status, models.SmsStatusSent)
+	}
+}
+
+func TestReceiveSmsDeliveryReportUnknownMessage(t *testing.T) {
+	router, _ := setupSmsWebhook(t)
+
+	w := postDeliveryReport(router, testWebhookSecret, helpers.SmsDeliveryReport{MessageID: "msg-unknown", Status: "delivered"})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want 404", w.Code)
+	}
+}
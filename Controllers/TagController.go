@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultTrendingDays = 7
+	maxTrendingDays     = 30
+)
+
+type TrendingTag struct {
+	Tag   string `json:"tag"`
+	Posts int64  `json:"posts"`
+}
+
+// syncPostText re-parses the post's title and description, replacing its
+// tags and mentions, and returns the users mentioned for the first time
+func syncPostText(tx *gorm.DB, post models.Post) ([]uint, error) {
+	if err := syncPostTags(tx, post); err != nil {
+		return nil, err
+	}
+	return syncPostMentions(tx, post)
+}
+
+func syncPostTags(tx *gorm.DB, post models.Post) error {
+	names := helpers.ParseTags(post.Title, post.Description)
+
+	var tagIDs []uint
+	if len(names) > 0 {
+		tags := make([]models.Tag, len(names))
+		for i, name := range names {
+			tags[i] = models.Tag{Name: name}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Tag{}).Where("name IN ?", names).Pluck("id", &tagIDs).Error; err != nil {
+			return err
+		}
+	}
+
+	// Kept tags keep their original CreatedAt so editing a post does not make its tags trend again
+	stale := tx.Where("post_id = ?", post.ID)
+	if len(tagIDs) > 0 {
+		stale = stale.Where("tag_id NOT IN ?", tagIDs)
+	}
+	if err := stale.Delete(&models.PostTag{}).Error; err != nil {
+		return err
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	links := make([]models.PostTag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		links[i] = models.PostTag{PostID: post.ID, TagID: tagID}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+func syncPostMentions(tx *gorm.DB, post models.Post) ([]uint, error) {
+	userIDs, err := resolveMentions(tx, helpers.ParseMentions(post.Title, post.Description))
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []uint
+	if err := tx.Model(&models.PostMention{}).Where("post_id = ?", post.ID).Pluck("user_id", &existing).Error; err != nil {
+		return nil, err
+	}
+
+	stale := tx.Where("post_id = ?", post.ID)
+	if len(userIDs) > 0 {
+		stale = stale.Where("user_id NOT IN ?", userIDs)
+	}
+	if err := stale.Delete(&models.PostMention{}).Error; err != nil {
+		return nil, err
+	}
+
+	alreadyMentioned := map[uint]bool{}
+	for _, userID := range existing {
+		alreadyMentioned[userID] = true
+	}
+	added := []uint{}
+	links := []models.PostMention{}
+	for _, userID := range userIDs {
+		if !alreadyMentioned[userID] {
+			added = append(added, userID)
+			links = append(links, models.PostMention{PostID: post.ID, UserID: userID})
+		}
+	}
+	if len(links) == 0 {
+		return added, nil
+	}
+	return added, tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// resolveMentions finds the users whose email starts with each handle
+// followed by @. Handles shared by several users are ambiguous and skipped.
+func resolveMentions(tx *gorm.DB, handles []string) ([]uint, error) {
+	if len(handles) == 0 {
+		return nil, nil
+	}
+
+	var users []models.User
+	if err := tx.Select("id", "email").Where("LOWER(SPLIT_PART(email, '@', 1)) IN ?", handles).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byHandle := map[string][]uint{}
+	for _, user := range users {
+		handle := strings.ToLower(strings.SplitN(user.Email, "@", 2)[0])
+		byHandle[handle] = append(byHandle[handle], user.ID)
+	}
+
+	userIDs := []uint{}
+	for _, handle := range handles {
+		if matches := byHandle[handle]; len(matches) == 1 {
+			userIDs = append(userIDs, matches[0])
+		}
+	}
+	return userIDs, nil
+}
+
+// notifyMentions tells newly mentioned users about the post, except its author
+func notifyMentions(post models.Post, author models.User, userIDs []uint) {
+	recipients := []uint{}
+	for _, userID := range userIDs {
+		if userID != author.ID {
+			recipients = append(recipients, userID)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+	notifications.DispatchAsync(recipients, notifications.MentionMessage(post, author))
+}
+
+// postTagNames reads the tags of each post, sorted by name
+func postTagNames(postIDs []uint) map[uint][]string {
+	names := map[uint][]string{}
+	for _, postID := range postIDs {
+		names[postID] = []string{}
+	}
+	if len(postIDs) == 0 {
+		return names
+	}
+
+	var rows []struct {
+		PostID uint
+		Name   string
+	}
+	initializers.DB.Table("post_tags").
+		Select("post_tags.post_id, tags.name").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Where("post_tags.post_id IN ?", postIDs).
+		Order("tags.name").
+		Scan(&rows)
+	for _, row := range rows {
+		names[row.PostID] = append(names[row.PostID], row.Name)
+	}
+	return names
+}
+
+// GetTagPosts pages through the posts with a tag, newest first, using the
+// same cursors as the latest feed
+func GetTagPosts(c *gin.Context) {
+	limit, ok := limitParam(c)
+	if !ok {
+		return
+	}
+	cursor, ok := feedCursorParam(c, FeedLatest)
+	if !ok {
+		return
+	}
+
+	tag := helpers.NormalizeTag(c.Param("tag"))
+	tagged := initializers.DB.Table("post_tags").
+		Select("post_tags.post_id").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Where("tags.name = ?", tag)
+
+	posts, next, err := latestFeedPage(initializers.DB.Scopes(publishedPosts(c)).Where("id IN (?)", tagged), cursor, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch posts")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tag":        tag,
+		"posts":      toPostResponses(posts, c.GetUint("userID")),
+		"nextCursor": encodeFeedCursor(next),
+	})
+}
+
+// GetTrendingTags lists the tags added to the most posts in the last ?days=
+// (default 7). A tag counts from when it was added or, for posts written as
+// drafts, from when the post was published.
+func GetTrendingTags(c *gin.Context) {
+	limit, ok := limitParam(c)
+	if !ok {
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultTrendingDays)))
+	if err != nil || days < 1 || days > maxTrendingDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid days: must be between 1 and ") + strconv.Itoa(maxTrendingDays)})
+		return
+	}
+
+	trending := []TrendingTag{}
+	if err := initializers.DB.Table("post_tags").
+		Select("tags.name AS tag, COUNT(*) AS posts").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Joins("JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.hidden_at IS NULL AND posts.status = ?", models.PostStatusPublished).
+		Where("GREATEST(post_tags.created_at, posts.publish_at) > ?", time.Now().AddDate(0, 0, -days)).
+		Group("tags.name").
+		Order("posts DESC, tags.name").
+		Limit(limit).
+		Scan(&trending).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch trending tags")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": trending, "days": days})
+}
@@ -1,18 +1,21 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
 	initializers "github.com/group4/campus-connect-api/Initializers"
 	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
 )
 
 func GetTimetables(c *gin.Context) {
 	var timetables []models.Timetable
 	if err := initializers.DB.Find(&timetables).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timetables"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch timetables")})
 		return
 	}
 	c.JSON(http.StatusOK, timetables)
@@ -21,12 +24,12 @@ func GetTimetables(c *gin.Context) {
 func CreateTimetable(c *gin.Context) {
 	var timetable models.Timetable
 	if err := c.ShouldBindJSON(&timetable); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
 		return
 	}
 
 	if err := initializers.DB.Create(&timetable).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create timetable: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to create timetable: ") + err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, timetable)
@@ -35,13 +38,13 @@ func CreateTimetable(c *gin.Context) {
 func GetTimetableByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timetable ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid timetable ID")})
 		return
 	}
 
 	var timetable models.Timetable
 	if err := initializers.DB.First(&timetable, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Timetable not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Timetable not found")})
 		return
 	}
 	c.JSON(http.StatusOK, timetable)
@@ -50,22 +53,28 @@ func GetTimetableByID(c *gin.Context) {
 func UpdateTimetable(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timetable ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid timetable ID")})
 		return
 	}
 
 	var timetable models.Timetable
 	if err := initializers.DB.First(&timetable, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Timetable not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Timetable not found")})
 		return
 	}
 
 	var updatedTimetable models.Timetable
 	if err := c.ShouldBindJSON(&updatedTimetable); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
 		return
 	}
 
+	oldCourse := timetable.Course
+	moved := timetable.Day != updatedTimetable.Day ||
+		!timetable.Time.Equal(updatedTimetable.Time) ||
+		timetable.Room != updatedTimetable.Room ||
+		timetable.Course != updatedTimetable.Course
+
 	timetable.Day = updatedTimetable.Day
 	timetable.Subject = updatedTimetable.Subject
 	timetable.SubjectCode = updatedTimetable.SubjectCode
@@ -73,29 +82,55 @@ func UpdateTimetable(c *gin.Context) {
 	timetable.Room = updatedTimetable.Room
 	timetable.Time = updatedTimetable.Time
 	timetable.Instructor = updatedTimetable.Instructor
+	timetable.Course = updatedTimetable.Course
 
 	if err := initializers.DB.Save(&timetable).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update timetable"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update timetable")})
 		return
 	}
+
+	// Let students on the course know their class moved. When it moved to
+	// another course, the students of the old course are told as well.
+	var courses []string
+	for _, course := range []string{oldCourse, timetable.Course} {
+		if course != "" && (len(courses) == 0 || courses[0] != course) {
+			courses = append(courses, course)
+		}
+	}
+	if moved && len(courses) > 0 {
+		var userIDs []uint
+		initializers.DB.Model(&models.User{}).Where("course IN ?", courses).Pluck("id", &userIDs)
+		notifications.DispatchAsync(userIDs, notifications.Message{
+			Category: models.NotificationCategoryTimetable,
+			Template: helpers.MessageTimetableChanged,
+			Data: map[string]interface{}{
+				"Subject":     timetable.Subject,
+				"SubjectCode": timetable.SubjectCode,
+				"Day":         timetable.Day,
+				"Time":        timetable.Time,
+				"Room":        timetable.Room,
+			},
+			Link: fmt.Sprintf("/api/timetables/%d", timetable.ID),
+		})
+	}
 	c.JSON(http.StatusOK, timetable)
 }
 
 func DeleteTimetable(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timetable ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid timetable ID")})
 		return
 	}
 
 	var timetable models.Timetable
 	if err := initializers.DB.First(&timetable, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Timetable not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "Timetable not found")})
 		return
 	}
 
 	if err := initializers.DB.Delete(&timetable).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete timetable"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to delete timetable")})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Timetable deleted successfully"})
@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer        = "Campus Connect"
+	recoveryCodeCount = 10
+)
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery
+// code. TOTP codes are rejected if their time step was already used.
+func verifySecondFactor(user *models.User, code, recoveryCode string) bool {
+	if code != "" {
+		step, ok := helpers.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+		if !ok || step <= user.TOTPLastStep {
+			return false
+		}
+		result := initializers.DB.Model(&models.User{}).
+			Where("id = ? AND totp_last_step < ?", user.ID, step).
+			Update("totp_last_step", step)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return false
+		}
+		user.TOTPLastStep = step
+		return true
+	}
+
+	if recoveryCode != "" {
+		hash := helpers.HashToken(strings.ToLower(strings.TrimSpace(recoveryCode)))
+		result := initializers.DB.Model(&models.RecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hash).
+			Update("used_at", time.Now())
+		return result.Error == nil && result.RowsAffected == 1
+	}
+
+	return false
+}
+
+// checkSecondFactor verifies a code for the signed-in caller like
+// verifySecondFactor, but counts wrong codes toward the account lockout as
+// LoginTwoFactor does, so codes cannot be guessed without limit. It writes a
+// 429 while the account is locked or backing off, and failStatus for a wrong code.
+func checkSecondFactor(c *gin.Context, user *models.User, code, recoveryCode string, failStatus int) bool {
+	if secondFactorBlocked(c, *user) {
+		return false
+	}
+	if !verifySecondFactor(user, code, recoveryCode) {
+		recordLoginAttempt(user, user.Email, c.ClientIP(), false)
+		c.JSON(failStatus, gin.H{"error": localize(c, "Invalid two-factor code")})
+		return false
+	}
+	recordLoginAttempt(user, user.Email, c.ClientIP(), true)
+	return true
+}
+
+// secondFactorBlocked writes a 429 and returns true while the account is
+// locked or backing off after failed attempts
+func secondFactorBlocked(c *gin.Context, user models.User) bool {
+	wait := accountRetryAfter(user)
+	if user.Locked() {
+		if remaining := time.Until(*user.LockedUntil); remaining > wait {
+			wait = remaining
+		}
+	}
+	if wait <= 0 {
+		return false
+	}
+	tooManyLoginAttempts(c, wait)
+	return true
+}
+
+// replaceRecoveryCodes discards the user's recovery codes and stores a fresh set
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	codes, err := helpers.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if err := tx.Create(&models.RecoveryCode{UserID: userID, CodeHash: helpers.HashToken(code)}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+// SetupTwoFactor generates a new TOTP secret for the caller. It only takes
+// effect once confirmed with ConfirmTwoFactor.
+func SetupTwoFactor(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	if user.TwoFactorEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Two-factor authentication is already enabled")})
+		return
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to generate secret")})
+		return
+	}
+
+	if err := initializers.DB.Model(&user).Updates(map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to save secret")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":     secret,
+		"otpauthUri": helpers.TOTPURI(totpIssuer, user.Email, secret),
+	})
+}
+
+// ConfirmTwoFactor enables two-factor login once the caller proves their app
+// produces valid codes, and returns a set of recovery codes shown only once
+func ConfirmTwoFactor(c *gin.Context) {
+	type ConfirmTwoFactorRequest struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	var req ConfirmTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if user.TwoFactorEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Two-factor authentication is already enabled")})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Start two-factor setup first")})
+		return
+	}
+
+	if !checkSecondFactor(c, &user, req.Code, "", http.StatusBadRequest) {
+		return
+	}
+
+	var codes []string
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("totp_enabled_at", time.Now()).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to enable two-factor authentication")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+// DisableTwoFactor turns off two-factor login. It needs the password and a current code or recovery code.
+func DisableTwoFactor(c *gin.Context) {
+	type DisableTwoFactorRequest struct {
+		Password     string `json:"password" binding:"required"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recoveryCode"`
+	}
+
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if !user.TwoFactorEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Two-factor authentication is not enabled")})
+		return
+	}
+
+	if secondFactorBlocked(c, user) {
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		recordLoginAttempt(&user, user.Email, c.ClientIP(), false)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid credentials")})
+		return
+	}
+	if !checkSecondFactor(c, &user, req.Code, req.RecoveryCode, http.StatusUnauthorized) {
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_secret":     "",
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to disable two-factor authentication")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes. It needs a current TOTP code.
+func RegenerateRecoveryCodes(c *gin.Context) {
+	type RegenerateRecoveryCodesRequest struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	var req RegenerateRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if !user.TwoFactorEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Two-factor authentication is not enabled")})
+		return
+	}
+	if !checkSecondFactor(c, &user, req.Code, "", http.StatusUnauthorized) {
+		return
+	}
+
+	var codes []string
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to generate recovery codes")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// LoginTwoFactor completes a login started by Login for an account with
+// two-factor enabled. Wrong codes count toward the account lockout.
+func LoginTwoFactor(c *gin.Context) {
+	type LoginTwoFactorRequest struct {
+		ChallengeToken string `json:"challengeToken" binding:"required"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recoveryCode"`
+	}
+
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	userID, err := helpers.ParseChallengeToken(req.ChallengeToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid or expired challenge token")})
+		return
+	}
+
+	var user models.User
+	if err := initializers.DB.First(&user, userID).Error; err != nil || !user.TwoFactorEnabled() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid or expired challenge token")})
+		return
+	}
+
+	ip := c.ClientIP()
+	if user.Locked() {
+		accountLocked(c)
+		return
+	}
+	// The challenge token shows the password was right, so the account's backoff can be reported
+	wait := loginRetryAfter(ip)
+	if accountWait := accountRetryAfter(user); accountWait > wait {
+		wait = accountWait
+	}
+	if wait > 0 {
+		tooManyLoginAttempts(c, wait)
+		return
+	}
+
+	if !verifySecondFactor(&user, req.Code, req.RecoveryCode) {
+		recordLoginAttempt(&user, user.Email, ip, false)
+		if user.Locked() {
+			accountLocked(c)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid two-factor code")})
+		return
+	}
+
+	recordLoginAttempt(&user, user.Email, ip, true)
+	completeLogin(c, user)
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+func TestConfirmTwoFactorBacksOffAfterWrongCodes(t *testing.T) {
+	db := useTestDB(t, &models.User{}, &models.LoginAttempt{}, &models.AccountLockout{}, &models.RecoveryCode{})
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generate secret: %v", err)
+	}
+	user := models.User{Name: "Staff", Email: "staff@example.com", Phone: "+255700000002", Role: models.RoleStaff, TOTPSecret: secret}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/user/2fa/confirm", func(c *gin.Context) {
+		var current models.User
+		initializers.DB.First(&current, user.ID)
+		c.Set("userID", current.ID)
+		c.Set("user", current)
+	}, ConfirmTwoFactor)
+	confirm := func(code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/2fa/confirm", bytes.NewBufferString(`{"code":"`+code+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := confirm("abcdef"); w.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d for a wrong code, want 400: %s", w.Code, w.Body)
+	}
+	w := confirm("abcdef")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d for a second code straight away, want 429: %s", w.Code, w.Body)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("429 has no Retry-After header")
+	}
+
+	var got models.User
+	initializers.DB.First(&got, user.ID)
+	if got.FailedLogins != 1 || got.TwoFactorEnabled() {
+		t.Errorf("user has %d failures and two-factor enabled %v, want 1 failure and disabled", got.FailedLogins, got.TwoFactorEnabled())
+	}
+}
@@ -1,54 +1,144 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/group4/campus-connect-api/Helpers"
 	initializers "github.com/group4/campus-connect-api/Initializers"
 	models "github.com/group4/campus-connect-api/Models"
+	notifications "github.com/group4/campus-connect-api/Notifications"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// PrivateUserResponse adds contact and account details that only the owner or an admin may see
+type PrivateUserResponse struct {
+	UserResponse
+	Email            string     `json:"email"`
+	Phone            string     `json:"phone"`
+	PhoneVerified    bool       `json:"phoneVerified"`
+	TwoFactorEnabled bool       `json:"twoFactorEnabled"`
+	Locale           string     `json:"locale"`
+	SuspendedUntil   *time.Time `json:"suspendedUntil"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+func toUserResponse(user models.User) UserResponse {
+	return UserResponse{
+		ID:           user.ID,
+		Name:         user.Name,
+		ProfileImage: user.ProfileImage,
+		Role:         user.Role,
+		Course:       user.Course,
+		Year:         user.Year,
+	}
+}
+
+func toPrivateUserResponse(user models.User) PrivateUserResponse {
+	return PrivateUserResponse{
+		UserResponse:     toUserResponse(user),
+		Email:            user.Email,
+		Phone:            user.Phone,
+		PhoneVerified:    user.PhoneVerified(),
+		TwoFactorEnabled: user.TwoFactorEnabled(),
+		Locale:           user.Locale,
+		SuspendedUntil:   user.SuspendedUntil,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+}
+
+// userResponseFor returns the private view to the user themselves or an admin
+// and the public view to everyone else, including anonymous callers
+func userResponseFor(c *gin.Context, user models.User) interface{} {
+	if _, authenticated := c.Get("userID"); authenticated && canModifyUser(c, user.ID) {
+		return toPrivateUserResponse(user)
+	}
+	return toUserResponse(user)
+}
+
 // User Controller (Modified)
 func GetUsers(c *gin.Context) {
 	var users []models.User
 	if err := initializers.DB.Find(&users).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to fetch users")})
 		return
 	}
-	c.JSON(http.StatusOK, users)
+
+	userResponses := []interface{}{}
+	for _, user := range users {
+		userResponses = append(userResponses, userResponseFor(c, user))
+	}
+	c.JSON(http.StatusOK, userResponses)
+}
+
+// userRequest reads a user from a request body. The model never serializes its
+// password hash, so the password field is bound separately.
+type userRequest struct {
+	models.User
+	Password string `json:"password"`
 }
 
 func CreateUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+	var req userRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+	user := req.User
+	user.Password = req.Password
+
+	// Default to the language the client asked for
+	if user.Locale == "" {
+		user.Locale = helpers.RequestLocale(c.GetHeader("Accept-Language"), "")
+	} else if !helpers.SupportedLocale(user.Locale) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid locale: ") + user.Locale})
+		return
+	}
+
+	phone, err := helpers.NormalizePhone(user.Phone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid phone number: ") + user.Phone})
 		return
 	}
+	user.Phone = phone
 
 	// Check if email already exists
 	var existingUser models.User
 	if err := initializers.DB.Where("email = ?", user.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email already registered"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Email already registered")})
+		return
+	}
+	if phoneTaken(user.Phone, 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Phone number already registered")})
 		return
 	}
 
 	// Hash the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to process password")})
 		return
 	}
 	user.Password = string(hashedPassword)
 
+	// Self-registered accounts are always students; admins assign other roles
+	user.Role = models.RoleStudent
+	user.PhoneVerifiedAt = nil
+	user.FailedLogins = 0
+	user.LastFailedLoginAt = nil
+	user.LockedUntil = nil
+
 	base64Image := user.ProfileImage
 	user.ProfileImage = "" 
 
 	if err := initializers.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to create user: ") + err.Error()})
 		return
 	}
 
@@ -58,7 +148,7 @@ func CreateUser(c *gin.Context) {
 		imagePath, err := helpers.SaveImage(base64Image, filename)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "User created but failed to save profile image: " + err.Error(),
+				"error":   localize(c, "User created but failed to save profile image: ") + err.Error(),
 				"id":      user.ID,
 				"name":    user.Name,
 				"email":   user.Email,
@@ -69,7 +159,7 @@ func CreateUser(c *gin.Context) {
 		user.ProfileImage = imagePath
 		if err := initializers.DB.Save(&user).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "User created but failed to update profile image path: " + err.Error(),
+				"error":   localize(c, "User created but failed to update profile image path: ") + err.Error(),
 				"id":      user.ID,
 				"name":    user.Name,
 				"email":   user.Email,
@@ -79,64 +169,165 @@ func CreateUser(c *gin.Context) {
 		}
 	}
 
-	// Send SMS after successful user creation
-	message := fmt.Sprintf("Hello %s, your registration was successful!", user.Name)
-	recipients := []string{user.Phone}
-	go func() {
-		err := helpers.SendRegistrationSMS(message, recipients)
-		if err != nil {
-			fmt.Println("Failed to send SMS:", err)
-		}
-	}()
+	// Send the phone verification code after successful user creation
+	sendPhoneVerification(user, helpers.MessageRegistrationSMS)
+
+	if err := notifications.QueueTemplatedEmail(user.Email, user.Locale, helpers.EmailTemplateRegistration, map[string]interface{}{"Name": user.Name}); err != nil {
+		fmt.Println("Failed to queue welcome email:", err)
+	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"id":           user.ID,
-		"name":         user.Name,
-		"email":        user.Email,
-		"role":         user.Role,
-		"profileImage": user.ProfileImage,
+		"id":            user.ID,
+		"name":          user.Name,
+		"email":         user.Email,
+		"role":          user.Role,
+		"profileImage":  user.ProfileImage,
+		"phoneVerified": user.PhoneVerified(),
 	})
 }
 
+// VerifyPhone confirms the caller's phone number with the code sent at registration
+func VerifyPhone(c *gin.Context) {
+	type VerifyPhoneRequest struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	var req VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	if user.PhoneVerified() {
+		c.JSON(http.StatusOK, gin.H{"message": "Phone number already verified"})
+		return
+	}
+
+	if err := consumeOTP(initializers.DB, user, models.OTPPurposePhoneVerification, req.Code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid or expired code")})
+		return
+	}
+
+	if err := initializers.DB.Model(&user).Update("phone_verified_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to verify phone number")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Phone number verified successfully"})
+}
+
+// ResendPhoneVerification sends the caller a new phone verification code
+func ResendPhoneVerification(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	if user.PhoneVerified() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Phone number already verified")})
+		return
+	}
+
+	code, err := issueOTP(user, models.OTPPurposePhoneVerification)
+	if errors.Is(err, errOTPRateLimited) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": localize(c, "Too many verification requests, try again later")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to create verification code")})
+		return
+	}
+
+	data := map[string]interface{}{"Name": user.Name, "Code": code}
+	if err := notifications.QueueCodeSMS(user.Locale, helpers.MessagePhoneVerificationSMS, data, []string{user.Phone}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to send verification code")})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code sent"})
+}
+
 func GetUserByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid user ID")})
 		return
 	}
 
 	var user models.User
 	if err := initializers.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "User not found")})
 		return
 	}
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, userResponseFor(c, user))
 }
 
 func UpdateUser(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid user ID")})
+		return
+	}
+
+	if !canModifyUser(c, uint(id)) {
+		forbid(c, "you can only update your own profile")
 		return
 	}
 
 	var user models.User
 	if err := initializers.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "User not found")})
 		return
 	}
 
-	var updatedUser models.User
-	if err := c.ShouldBindJSON(&updatedUser); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+	var req userRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
 		return
 	}
+	updatedUser := req.User
+	updatedUser.Password = req.Password
+
+	// Handle role change: only admins may assign roles
+	if updatedUser.Role != "" && updatedUser.Role != user.Role {
+		if !callerCan(c, models.PermManageRoles) {
+			forbid(c, "only admins can change roles")
+			return
+		}
+		if !models.ValidRole(updatedUser.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid role: must be one of student, lecturer, staff, moderator, admin")})
+			return
+		}
+		user.Role = updatedUser.Role
+	}
+
+	// A new number has to be verified again
+	phoneChanged := false
+	if updatedUser.Phone != "" {
+		phone, err := helpers.NormalizePhone(updatedUser.Phone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid phone number: ") + updatedUser.Phone})
+			return
+		}
+		if phone != user.Phone {
+			if phoneTaken(phone, user.ID) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Phone number already registered")})
+				return
+			}
+			user.Phone = phone
+			user.PhoneVerifiedAt = nil
+			phoneChanged = true
+		}
+	}
+
+	if updatedUser.Locale != "" {
+		if !helpers.SupportedLocale(updatedUser.Locale) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid locale: ") + updatedUser.Locale})
+			return
+		}
+		user.Locale = updatedUser.Locale
+	}
 
 	// Handle password update
 	if updatedUser.Password != "" {
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updatedUser.Password), bcrypt.DefaultCost)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to process password")})
 			return
 		}
 		user.Password = string(hashedPassword)
@@ -150,7 +341,7 @@ func UpdateUser(c *gin.Context) {
 				// Log warning but proceed
 				c.JSON(http.StatusOK, gin.H{
 					"warning": "Failed to delete old profile image: " + err.Error(),
-					"user":    user,
+					"user":    toPrivateUserResponse(user),
 				})
 			}
 		}
@@ -158,7 +349,7 @@ func UpdateUser(c *gin.Context) {
 		filename := helpers.SanitizeUserIDFilename("profile-picture-UID", uint(id))
 		imagePath, err := helpers.SaveImage(updatedUser.ProfileImage, filename)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save profile image: " + err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to save profile image: ") + err.Error()})
 			return
 		}
 		user.ProfileImage = imagePath
@@ -166,28 +357,58 @@ func UpdateUser(c *gin.Context) {
 
 	// Update fields
 	user.Name = updatedUser.Name
-	user.Role = updatedUser.Role
 	user.Course = updatedUser.Course
 	user.Year = updatedUser.Year
 	user.Email = updatedUser.Email
 
 	if err := initializers.DB.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to update user")})
+		return
+	}
+
+	if phoneChanged {
+		sendPhoneVerification(user, helpers.MessagePhoneVerificationSMS)
+	}
+	c.JSON(http.StatusOK, toPrivateUserResponse(user))
+}
+
+// phoneTaken reports whether another account, including a deleted one still
+// holding the unique number, uses the phone number
+func phoneTaken(phone string, exceptUserID uint) bool {
+	var count int64
+	initializers.DB.Unscoped().Model(&models.User{}).Where("phone = ? AND id <> ?", phone, exceptUserID).Count(&count)
+	return count > 0
+}
+
+// sendPhoneVerification issues a verification code and queues it by SMS,
+// logging rather than failing the request when that does not work
+func sendPhoneVerification(user models.User, template string) {
+	code, err := issueOTP(user, models.OTPPurposePhoneVerification)
+	if err != nil {
+		fmt.Println("Failed to create phone verification code:", err)
 		return
 	}
-	c.JSON(http.StatusOK, user)
+	data := map[string]interface{}{"Name": user.Name, "Code": code}
+	if err := notifications.QueueCodeSMS(user.Locale, template, data, []string{user.Phone}); err != nil {
+		fmt.Println("Failed to queue SMS:", err)
+	}
 }
 
 func DeleteUser(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid user ID")})
+		return
+	}
+
+	if !canModifyUser(c, uint(id)) {
+		forbid(c, "you can only delete your own account")
 		return
 	}
 
 	var user models.User
 	if err := initializers.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": localize(c, "User not found")})
 		return
 	}
 
@@ -204,7 +425,7 @@ func DeleteUser(c *gin.Context) {
 	}
 
 	if err := initializers.DB.Delete(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to delete user")})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
@@ -218,31 +439,84 @@ func Login(c *gin.Context) {
 
 	var loginReq LoginRequest
 	if err := c.ShouldBindJSON(&loginReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid input: ") + err.Error()})
 		return
 	}
 
+	ip := c.ClientIP()
+
 	var user models.User
-	if err := initializers.DB.Where("email = ?", loginReq.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	var account *models.User
+	if err := initializers.DB.Where("email = ?", loginReq.Email).First(&user).Error; err == nil {
+		account = &user
+	}
+
+	if wait := loginRetryAfter(ip); wait > 0 {
+		tooManyLoginAttempts(c, wait)
+		return
+	}
+
+	// Only the IP limit answers 429: unknown emails have no backoff to report
+	if account != nil && (user.Locked() || accountRetryAfter(user) > 0) {
+		accountLocked(c)
+		return
+	}
+
+	if account == nil {
+		recordLoginAttempt(nil, loginReq.Email, ip, false)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid credentials")})
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginReq.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		recordLoginAttempt(&user, loginReq.Email, ip, false)
+		if user.Locked() {
+			accountLocked(c)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": localize(c, "Invalid credentials")})
+		return
+	}
+
+	// Accounts with two-factor enabled must finish at /api/user/login/2fa
+	if user.TwoFactorEnabled() {
+		challengeToken, expiresAt, err := helpers.GenerateChallengeToken(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to start two-factor login")})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Two-factor code required",
+			"twoFactorRequired": true,
+			"challengeToken":    challengeToken,
+			"expiresAt":         expiresAt,
+		})
+		return
+	}
+
+	recordLoginAttempt(&user, loginReq.Email, ip, true)
+	completeLogin(c, user)
+}
+
+// completeLogin starts a session and writes the successful login response
+func completeLogin(c *gin.Context, user models.User) {
+	if user.Suspended() {
+		accountSuspended(c, user)
+		return
+	}
+
+	tokens, err := startSession(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": localize(c, "Failed to start session: ") + err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"user": gin.H{
-			"id":           user.ID,
-			"name":         user.Name,
-			"email":        user.Email,
-			"role":         user.Role,
-			"course":       user.Course,
-			"year":         user.Year,
-			"profileImage": user.ProfileImage,
-		},
+		"message":      "Login successful",
+		"accessToken":  tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    "Bearer",
+		"expiresAt":    tokens.ExpiresAt,
+		"user":         toPrivateUserResponse(user),
 	})
 }
\ No newline at end of file
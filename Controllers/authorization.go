@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
+)
+
+// forbid writes the standard 403 response used by every ownership and permission check
+func forbid(c *gin.Context, reason string) {
+	c.JSON(http.StatusForbidden, gin.H{"error": localize(c, "Forbidden: "+reason)})
+}
+
+// callerCan reports whether the authenticated caller's role grants perm
+func callerCan(c *gin.Context, perm models.Permission) bool {
+	return models.HasPermission(c.GetString("role"), perm)
+}
+
+// canModifyPost allows the post's author, and moderators and admins once the post is published
+func canModifyPost(c *gin.Context, post models.Post) bool {
+	if post.UserID == c.GetUint("userID") {
+		return true
+	}
+	return post.Status == models.PostStatusPublished && callerCan(c, models.PermModeratePosts)
+}
+
+// visiblePosts scopes a posts query to what the caller may see: drafts and
+// scheduled posts are left out for everyone but their author, and hidden
+// posts for everyone but moderators and admins
+func visiblePosts(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("posts.status = ? OR posts.user_id = ?", models.PostStatusPublished, c.GetUint("userID"))
+		if callerCan(c, models.PermModeratePosts) {
+			return db
+		}
+		return db.Where("posts.hidden_at IS NULL")
+	}
+}
+
+// publishedPosts is visiblePosts for listings, which leave out the caller's
+// own drafts and scheduled posts too
+func publishedPosts(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("posts.status = ?", models.PostStatusPublished)
+		if callerCan(c, models.PermModeratePosts) {
+			return db
+		}
+		return db.Where("posts.hidden_at IS NULL")
+	}
+}
+
+// visibleComments is visiblePosts for comments
+func visibleComments(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if callerCan(c, models.PermModeratePosts) {
+			return db
+		}
+		return db.Where("comments.hidden_at IS NULL")
+	}
+}
+
+// canModifyUser allows users to manage their own account and admins to manage any account
+func canModifyUser(c *gin.Context, userID uint) bool {
+	return userID == c.GetUint("userID") || callerCan(c, models.PermManageUsers)
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	"gorm.io/gorm"
+)
+
+// useTestDB points initializers.DB at a fresh in-memory database with tables
+// for the given models, restoring the previous database when the test ends
+func useTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("database handle: %v", err)
+	}
+	// Every connection to :memory: is a new database; keep to one
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	previous := initializers.DB
+	initializers.DB = db
+	t.Cleanup(func() { initializers.DB = previous })
+	return db
+}
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+)
+
+// requestLocale is the language to answer in: Accept-Language first, then the
+// caller's saved locale
+func requestLocale(c *gin.Context) string {
+	return helpers.RequestLocale(c.GetHeader("Accept-Language"), c.GetString("locale"))
+}
+
+// localize translates an API message into the caller's language
+func localize(c *gin.Context, text string) string {
+	return helpers.Translate(requestLocale(c), text)
+}
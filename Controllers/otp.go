@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	otpDigits         = 6
+	otpMaxAttempts    = 5
+	otpMaxPerHour     = 3
+	otpResendCooldown = time.Minute
+)
+
+var (
+	errOTPRateLimited = errors.New("too many codes requested, try again later")
+	errOTPInvalid     = errors.New("invalid or expired code")
+)
+
+// issueOTP creates a new code for the user and purpose, replacing any earlier
+// unused code. It returns the plaintext code so the caller can send it.
+func issueOTP(user models.User, purpose string) (string, error) {
+	now := time.Now()
+
+	var sentLastHour int64
+	if err := initializers.DB.Model(&models.OneTimeCode{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", user.ID, purpose, now.Add(-time.Hour)).
+		Count(&sentLastHour).Error; err != nil {
+		return "", err
+	}
+	if sentLastHour >= otpMaxPerHour {
+		return "", errOTPRateLimited
+	}
+
+	var latest models.OneTimeCode
+	err := initializers.DB.Where("user_id = ? AND purpose = ?", user.ID, purpose).Order("created_at DESC").First(&latest).Error
+	if err == nil && now.Sub(latest.CreatedAt) < otpResendCooldown {
+		return "", errOTPRateLimited
+	}
+
+	code, err := helpers.GenerateOTP(otpDigits)
+	if err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.OneTimeCode{}).
+			Where("user_id = ? AND purpose = ? AND consumed_at IS NULL", user.ID, purpose).
+			Update("consumed_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OneTimeCode{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			CodeHash:  string(hash),
+			ExpiresAt: now.Add(helpers.OTPTTL()),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// consumeOTP checks code against the user's active code for purpose and marks
+// it used on success. Every guess claims one of otpMaxAttempts before the code
+// is compared, so parallel guesses cannot get past the limit. db must not be a
+// transaction that is rolled back on failure, or the claimed attempt is lost.
+func consumeOTP(db *gorm.DB, user models.User, purpose, code string) error {
+	var otp models.OneTimeCode
+	if err := db.Where("user_id = ? AND purpose = ? AND consumed_at IS NULL AND expires_at > ?", user.ID, purpose, time.Now()).
+		Order("created_at DESC").
+		First(&otp).Error; err != nil {
+		return errOTPInvalid
+	}
+
+	claim := db.Model(&models.OneTimeCode{}).
+		Where("id = ? AND attempts < ?", otp.ID, otpMaxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if claim.Error != nil {
+		return claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		return errOTPInvalid
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(otp.CodeHash), []byte(code)); err != nil {
+		return errOTPInvalid
+	}
+
+	result := db.Model(&otp).Where("consumed_at IS NULL").Update("consumed_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errOTPInvalid
+	}
+	return nil
+}
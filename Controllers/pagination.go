@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageParams reads ?page= (from 1) and ?limit= and returns the limit and offset.
+// It writes a 400 and returns ok=false for invalid values.
+func pageParams(c *gin.Context) (page, limit, offset int, ok bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid page: must be a positive integer")})
+		return 0, 0, 0, false
+	}
+
+	limit, ok = limitParam(c)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	return page, limit, (page - 1) * limit, true
+}
+
+// limitParam reads ?limit=, writing a 400 and returning ok=false for invalid values
+func limitParam(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageSize)))
+	if err != nil || limit < 1 || limit > maxPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": localize(c, "Invalid limit: must be between 1 and ") + strconv.Itoa(maxPageSize)})
+		return 0, false
+	}
+	return limit, true
+}
+
+// encodeCursor packs the position after the last item of a page into an
+// opaque ?cursor= value for keyset pagination
+func encodeCursor(position interface{}) string {
+	data, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor unpacks a cursor made by encodeCursor into position
+func decodeCursor(cursor string, position interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, position)
+}
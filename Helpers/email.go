@@ -0,0 +1,65 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EmailMessage is one email with a plain text body and an optional HTML alternative
+type EmailMessage struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// EmailSender delivers an email. Name identifies the provider in logs.
+type EmailSender interface {
+	Name() string
+	Send(message EmailMessage) error
+}
+
+// Email is the sender used by SendEmail. It is set at startup by initializers.ConfigureEmail.
+var Email EmailSender
+
+// SendEmail sends an email through the configured provider
+func SendEmail(message EmailMessage) error {
+	if Email == nil {
+		return errors.New("email sender is not configured")
+	}
+	return Email.Send(message)
+}
+
+// AppURL is the public address links in emails point at, from APP_URL
+func AppURL() string {
+	if url := strings.TrimRight(os.Getenv("APP_URL"), "/"); url != "" {
+		return url
+	}
+	return "http://localhost:3000"
+}
+
+// NewEmailSenderFromEnv builds the sender selected by EMAIL_PROVIDER:
+// "smtp" to send through SMTP_HOST, "file" to append emails to EMAIL_FILE_PATH
+// for local development, or "memory" to keep them in memory for tests. Like
+// NewSmsSenderFromEnv it has no default, since the local senders keep reset codes.
+func NewEmailSenderFromEnv() (EmailSender, error) {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("EMAIL_PROVIDER")))
+	switch provider {
+	case "":
+		return nil, errors.New("EMAIL_PROVIDER is not set; choose smtp, file or memory")
+	case "smtp":
+		return NewSMTPSenderFromEnv()
+	case "file":
+		path := os.Getenv("EMAIL_FILE_PATH")
+		if path == "" {
+			path = "./tmp/email.log"
+		}
+		return &FileEmailSender{Path: path}, nil
+	case "memory":
+		return &MemoryEmailSender{}, nil
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_PROVIDER %q", provider)
+	}
+}
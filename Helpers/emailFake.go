@@ -0,0 +1,77 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SentEmail is an email captured by one of the local senders
+type SentEmail struct {
+	SentAt time.Time `json:"sentAt"`
+	EmailMessage
+}
+
+// FileEmailSender appends each email as a JSON line to Path instead of sending it.
+// Use it in development to read emails without an SMTP server.
+type FileEmailSender struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (f *FileEmailSender) Name() string {
+	return "file"
+}
+
+func (f *FileEmailSender) Send(message EmailMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create email log directory: %v", err)
+	}
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open email log: %v", err)
+	}
+	defer file.Close()
+
+	line, _ := json.Marshal(SentEmail{SentAt: time.Now(), EmailMessage: message})
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write email log: %v", err)
+	}
+	return nil
+}
+
+// MemoryEmailSender records emails in memory so tests can assert on them.
+// Set Err to make every send fail.
+type MemoryEmailSender struct {
+	Err  error
+	mu   sync.Mutex
+	sent []SentEmail
+}
+
+func (m *MemoryEmailSender) Name() string {
+	return "memory"
+}
+
+func (m *MemoryEmailSender) Send(message EmailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+	m.sent = append(m.sent, SentEmail{SentAt: time.Now(), EmailMessage: message})
+	return nil
+}
+
+// Sent returns a copy of every email recorded so far
+func (m *MemoryEmailSender) Sent() []SentEmail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SentEmail(nil), m.sent...)
+}
@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Email templates. Each has a .txt and a .html file per locale in
+// templates/email/<locale> that both define "subject"; the HTML body is
+// wrapped in that locale's layout.html.
+const (
+	EmailTemplateRegistration  = "registration"
+	EmailTemplatePasswordReset = "password_reset"
+	EmailTemplateEventReminder = "event_reminder"
+	EmailTemplateNotification  = "notification"
+)
+
+//go:embed templates/email
+var emailTemplateFiles embed.FS
+
+// RenderEmail fills the named template in the recipient's locale, falling back
+// to English. AppURL is added to data, and a relative Link becomes an absolute URL.
+func RenderEmail(to, locale, name string, data map[string]interface{}) (EmailMessage, error) {
+	values := map[string]interface{}{"AppURL": AppURL(), "URL": ""}
+	for key, value := range data {
+		values[key] = value
+	}
+	if link, ok := values["Link"].(string); ok && link != "" {
+		if strings.HasPrefix(link, "/") {
+			link = AppURL() + link
+		}
+		values["URL"] = link
+	}
+
+	if _, err := fs.Stat(emailTemplateFiles, "templates/email/"+locale+"/"+name+".txt"); err != nil {
+		locale = DefaultLocale
+	}
+	dir := "templates/email/" + locale + "/"
+
+	text, err := texttemplate.New(name).Funcs(templateFuncs(locale)).ParseFS(emailTemplateFiles, dir+name+".txt")
+	if err != nil {
+		return EmailMessage{}, fmt.Errorf("unknown email template %q: %v", name, err)
+	}
+	html, err := htmltemplate.New(name).Funcs(htmltemplate.FuncMap(templateFuncs(locale))).ParseFS(emailTemplateFiles, dir+"layout.html", dir+name+".html")
+	if err != nil {
+		return EmailMessage{}, fmt.Errorf("unknown email template %q: %v", name, err)
+	}
+
+	var subject, textBody, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", values); err != nil {
+		return EmailMessage{}, err
+	}
+	if err := text.ExecuteTemplate(&textBody, name+".txt", values); err != nil {
+		return EmailMessage{}, err
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "layout", values); err != nil {
+		return EmailMessage{}, err
+	}
+
+	return EmailMessage{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(textBody.String()) + "\n",
+		HTML:    htmlBody.String(),
+	}, nil
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import "testing"
+
+func TestNewEmailSenderFromEnvRequiresProvider(t *testing.T) {
+	t.Setenv("EMAIL_PROVIDER", "")
+	if sender, err := NewEmailSenderFromEnv(); err == nil {
+		t.Fatalf("got %s sender with EMAIL_PROVIDER unset, want an error", sender.Name())
+	}
+
+	t.Setenv("EMAIL_PROVIDER", "memory")
+	sender, err := NewEmailSenderFromEnv()
+	if err != nil {
+		t.Fatalf("EMAIL_PROVIDER=memory: %v", err)
+	}
+	if sender.Name() != "memory" {
+		t.Errorf("got %s sender, want memory", sender.Name())
+	}
+}
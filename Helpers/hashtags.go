@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Limits on what is parsed from one post, so a single post cannot spam
+// tags or notify an unbounded number of users
+const (
+	MaxTagsPerPost     = 20
+	MaxMentionsPerPost = 20
+)
+
+var (
+	// A tag is # followed by letters, digits and underscores, not inside a word or URL
+	tagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#/])#([\p{L}\p{N}_]{1,50})`)
+	// A mention is @ followed by the local part of a user's email, not inside an email address
+	mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])@([A-Za-z0-9][A-Za-z0-9._%+-]{0,63})`)
+)
+
+// NormalizeTag lowercases a tag and strips a leading #
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// ParseTags returns the unique #tags in the texts, lowercased and without the
+// #, in order of first appearance. Tags made only of digits or underscores
+// (e.g. "#1") are ignored.
+func ParseTags(texts ...string) []string {
+	return parseUnique(tagPattern, MaxTagsPerPost, texts, func(tag string) string {
+		if strings.IndexFunc(tag, unicode.IsLetter) < 0 {
+			return ""
+		}
+		return strings.ToLower(tag)
+	})
+}
+
+// ParseMentions returns the unique @handles in the texts, lowercased and
+// without the @, in order of first appearance. A handle is the part of a
+// user's email before the @.
+func ParseMentions(texts ...string) []string {
+	return parseUnique(mentionPattern, MaxMentionsPerPost, texts, func(handle string) string {
+		// Sentence punctuation after a mention is not part of it
+		return strings.ToLower(strings.TrimRight(handle, "."))
+	})
+}
+
+func parseUnique(pattern *regexp.Regexp, max int, texts []string, normalize func(string) string) []string {
+	found := []string{}
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+			value := normalize(match[1])
+			if value == "" || seen[value] {
+				continue
+			}
+			if len(found) == max {
+				return found
+			}
+			seen[value] = true
+			found = append(found, value)
+		}
+	}
+	return found
+}
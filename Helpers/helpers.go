@@ -1,30 +1,16 @@
 package helpers
 
 import (
-	"bytes"
+	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
-	"errors"
+	"encoding/hex"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 )
 
-type SmsRecipient struct {
-	Number int64 `json:"number"`
-}
-
-type SmsRequest struct {
-	SenderID   int            `json:"sender_id"`
-	SMS        string         `json:"sms"`
-	Schedule   string         `json:"schedule"`
-	Recipients []SmsRecipient `json:"recipients"`
-}
-
 // SaveImage saves a base64-encoded image to the Images folder and returns the relative file path
 func SaveImage(base64Image, filename string) (string, error) {
 	imageDir := "./Images"
@@ -118,66 +104,12 @@ func SanitizeUserIDFilename(prefix string, userID uint) string {
 	return filename
 }
 
-func SendRegistrationSMS(message string, recipients []string) error {
-	// Step 1: Authenticate to get the token
-	authBody := map[string]interface{}{
-		"user_id":  255787504956,
-		"password": "adventist145",
-	}
-	authJson, _ := json.Marshal(authBody)
-
-	authResp, err := http.Post("https://dev.hudumasms.com/api/create-token", "application/json", bytes.NewBuffer(authJson))
-	if err != nil {
-		return fmt.Errorf("failed to authenticate to SMS API: %w", err)
-	}
-	defer authResp.Body.Close()
-
-	var authResult struct {
-		Data struct {
-			Token string `json:"token"`
-		} `json:"data"`
+// UniqueFilename appends a random suffix to prefix, so files saved under the
+// same prefix never overwrite each other
+func UniqueFilename(prefix string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate file name: %v", err)
 	}
-	bodyBytes, _ := io.ReadAll(authResp.Body)
-	if err := json.Unmarshal(bodyBytes, &authResult); err != nil {
-		return fmt.Errorf("failed to parse token response: %w", err)
-	}
-	if authResult.Data.Token == "" {
-		return errors.New("token not received from SMS API")
-	}
-
-	// Step 2: Prepare recipients
-	var formattedRecipients []SmsRecipient
-	for _, rec := range recipients {
-		var number int64
-		fmt.Sscanf(rec, "%d", &number)
-		formattedRecipients = append(formattedRecipients, SmsRecipient{Number: number})
-	}
-
-	// Step 3: Send the SMS
-	smsBody := SmsRequest{
-		SenderID:   20,
-		SMS:        message,
-		Schedule:   "None",
-		Recipients: formattedRecipients,
-	}
-	smsJson, _ := json.Marshal(smsBody)
-
-	req, _ := http.NewRequest("POST", "https://dev.hudumasms.com/api/send-sms", bytes.NewBuffer(smsJson))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", "Bearer "+authResult.Data.Token)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send SMS: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("SMS API returned non-200 status: %d, %s", resp.StatusCode, string(respBody))
-	}
-
-	return nil
+	return prefix + "-" + hex.EncodeToString(suffix), nil
 }
@@ -0,0 +1,148 @@
+package helpers
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported locales. English is the fallback for anything missing in another locale.
+const (
+	LocaleEnglish = "en"
+	LocaleSwahili = "sw"
+	DefaultLocale = LocaleEnglish
+)
+
+// Locales lists the supported locales
+var Locales = []string{LocaleEnglish, LocaleSwahili}
+
+// SupportedLocale reports whether locale is one of Locales
+func SupportedLocale(locale string) bool {
+	for _, known := range Locales {
+		if locale == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeLocale reduces a language tag such as "sw-TZ" to a supported
+// locale, or returns "" if it is not supported
+func NormalizeLocale(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if SupportedLocale(tag) {
+		return tag
+	}
+	return ""
+}
+
+// RequestLocale picks the locale for a request: the most preferred supported
+// language in the Accept-Language header, else fallback (usually the caller's
+// saved locale), else DefaultLocale
+func RequestLocale(acceptLanguage, fallback string) string {
+	type choice struct {
+		locale string
+		q      float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		locale := NormalizeLocale(tag)
+		if locale == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			choices = append(choices, choice{locale, q})
+		}
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	if len(choices) > 0 {
+		return choices[0].locale
+	}
+	if locale := NormalizeLocale(fallback); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Translate returns text in the given locale. Messages built as a fixed prefix
+// ending in ": " followed by details are matched on the prefix. Text without a
+// translation is returned unchanged.
+func Translate(locale, text string) string {
+	catalog := errorTranslations[locale]
+	if catalog == nil {
+		return text
+	}
+	if translated, ok := catalog[text]; ok {
+		return translated
+	}
+	if i := strings.Index(text, ": "); i >= 0 {
+		if translated, ok := catalog[text[:i+2]]; ok {
+			return translated + text[i+2:]
+		}
+	}
+	return text
+}
+
+var swahiliWeekdays = map[time.Weekday]string{
+	time.Sunday:    "Jumapili",
+	time.Monday:    "Jumatatu",
+	time.Tuesday:   "Jumanne",
+	time.Wednesday: "Jumatano",
+	time.Thursday:  "Alhamisi",
+	time.Friday:    "Ijumaa",
+	time.Saturday:  "Jumamosi",
+}
+
+var swahiliMonths = map[time.Month]string{
+	time.January:   "Januari",
+	time.February:  "Februari",
+	time.March:     "Machi",
+	time.April:     "Aprili",
+	time.May:       "Mei",
+	time.June:      "Juni",
+	time.July:      "Julai",
+	time.August:    "Agosti",
+	time.September: "Septemba",
+	time.October:   "Oktoba",
+	time.November:  "Novemba",
+	time.December:  "Desemba",
+}
+
+// LocalWeekday names a weekday given in English ("Monday") in the locale.
+// Unknown names are returned unchanged.
+func LocalWeekday(locale, day string) string {
+	if locale != LocaleSwahili {
+		return day
+	}
+	for weekday, name := range swahiliWeekdays {
+		if strings.EqualFold(day, weekday.String()) {
+			return name
+		}
+	}
+	return day
+}
+
+// FormatDate formats a date as "Mon 2 Jan 2006" in the locale, with the time if withTime is set
+func FormatDate(locale string, t time.Time, withTime bool) string {
+	var date string
+	if locale == LocaleSwahili {
+		date = swahiliWeekdays[t.Weekday()] + " " + strconv.Itoa(t.Day()) + " " + swahiliMonths[t.Month()] + " " + strconv.Itoa(t.Year())
+	} else {
+		date = t.Format("Mon 2 Jan 2006")
+	}
+	if withTime {
+		date += " " + t.Format("15:04")
+	}
+	return date
+}
@@ -0,0 +1,219 @@
+package helpers
+
+// errorTranslations maps API error messages, keyed by their English text, to
+// other locales. Keys ending in ": " match messages with details appended.
+var errorTranslations = map[string]map[string]string{
+	LocaleSwahili: {
+		// Authentication and sessions
+		"Missing or malformed authorization header":     "Kichwa cha idhini hakipo au si sahihi",
+		"Invalid or expired token":                      "Tokeni si sahihi au imeisha muda wake",
+		"Session has been revoked":                      "Kipindi kimebatilishwa",
+		"Session has expired or been revoked":           "Kipindi kimeisha muda au kimebatilishwa",
+		"Invalid credentials":                           "Taarifa za kuingia si sahihi",
+		"Invalid refresh token":                         "Tokeni ya kuonyesha upya si sahihi",
+		"Refresh token reuse detected; session revoked": "Tokeni ya kuonyesha upya imetumika tena; kipindi kimebatilishwa",
+		"Failed to refresh session: ":                   "Imeshindwa kuonyesha upya kipindi: ",
+		"Failed to start session: ":                     "Imeshindwa kuanzisha kipindi: ",
+		"Failed to log out":                             "Imeshindwa kutoka",
+		"Failed to fetch sessions":                      "Imeshindwa kupata vipindi",
+		"Failed to revoke session":                      "Imeshindwa kubatilisha kipindi",
+		"Invalid session ID":                            "Kitambulisho cha kipindi si sahihi",
+		"Session not found":                             "Kipindi hakikupatikana",
+		"Too many login attempts, try again later":      "Majaribio mengi mno ya kuingia, jaribu tena baadaye",
+		"Account suspended":                             "Akaunti imesimamishwa",
+		"Failed to unlock user":                         "Imeshindwa kufungua mtumiaji",
+		"Failed to fetch lockouts":                      "Imeshindwa kupata orodha ya akaunti zilizofungwa",
+
+		// Permissions
+		"Forbidden: ":                                                                 "Hairuhusiwi: ",
+		"Forbidden: insufficient permissions":                                         "Hairuhusiwi: huna ruhusa za kutosha",
+		"Forbidden: verify your phone number first":                                   "Hairuhusiwi: thibitisha nambari yako ya simu kwanza",
+		"Forbidden: only admins can change roles":                                     "Hairuhusiwi: ni wasimamizi pekee wanaoweza kubadilisha majukumu",
+		"Forbidden: you can only update your own profile":                             "Hairuhusiwi: unaweza kubadilisha wasifu wako tu",
+		"Forbidden: you can only delete your own account":                             "Hairuhusiwi: unaweza kufuta akaunti yako tu",
+		"Forbidden: only the author, a moderator or an admin can edit this post":      "Hairuhusiwi: ni mwandishi, msimamizi wa maudhui au msimamizi pekee anayeweza kuhariri chapisho hili",
+		"Forbidden: only the author, a moderator or an admin can delete this post":    "Hairuhusiwi: ni mwandishi, msimamizi wa maudhui au msimamizi pekee anayeweza kufuta chapisho hili",
+		"Forbidden: post author cannot be changed":                                    "Hairuhusiwi: mwandishi wa chapisho hawezi kubadilishwa",
+		"Forbidden: moderators and admins cannot be suspended":                        "Hairuhusiwi: wasimamizi wa maudhui na wasimamizi hawawezi kusimamishwa",
+		"Forbidden: you can only edit your own comments":                              "Hairuhusiwi: unaweza kuhariri maoni yako tu",
+		"Forbidden: only the author, a moderator or an admin can delete this comment": "Hairuhusiwi: ni mwandishi, msimamizi wa maudhui au msimamizi pekee anayeweza kufuta maoni haya",
+
+		// Input
+		"Invalid input: ":                                         "Data si sahihi: ",
+		"Invalid page: must be a positive integer":                "Ukurasa si sahihi: lazima uwe namba chanya",
+		"Invalid limit: must be between 1 and ":                   "Kikomo si sahihi: lazima kiwe kati ya 1 na ",
+		"Invalid limit: must be between 1 and 500":                "Kikomo si sahihi: lazima kiwe kati ya 1 na 500",
+		"Invalid status: ":                                        "Hali si sahihi: ",
+		"Invalid category: ":                                      "Kundi si sahihi: ",
+		"Invalid timezone: ":                                      "Saa za eneo si sahihi: ",
+		"Invalid quiet hours: ":                                   "Saa za utulivu si sahihi: ",
+		"Invalid quiet hours: set both start and end, or neither": "Saa za utulivu si sahihi: weka mwanzo na mwisho, au usiweke kimoja",
+		"Invalid locale: ":                                        "Lugha si sahihi: ",
+		"Invalid role: ":                                          "Jukumu si sahihi: ",
+		"Invalid role: must be one of student, lecturer, staff, moderator, admin": "Jukumu si sahihi: lazima liwe student, lecturer, staff, moderator au admin",
+		"Invalid filter: set at least one of course, year or role":                "Kichujio si sahihi: weka angalau kozi, mwaka au jukumu",
+
+		// Users and phone verification
+		"Invalid user ID":                                        "Kitambulisho cha mtumiaji si sahihi",
+		"User not found":                                         "Mtumiaji hakupatikana",
+		"Invalid phone number: ":                                 "Nambari ya simu si sahihi: ",
+		"Phone number already registered":                        "Nambari ya simu tayari imesajiliwa",
+		"Email already registered":                               "Barua pepe tayari imesajiliwa",
+		"Failed to fetch users":                                  "Imeshindwa kupata watumiaji",
+		"Failed to create user: ":                                "Imeshindwa kuunda mtumiaji: ",
+		"Failed to update user":                                  "Imeshindwa kusasisha mtumiaji",
+		"Failed to delete user":                                  "Imeshindwa kufuta mtumiaji",
+		"Failed to process password":                             "Imeshindwa kuchakata nenosiri",
+		"Failed to save profile image: ":                         "Imeshindwa kuhifadhi picha ya wasifu: ",
+		"Failed to save image: ":                                 "Imeshindwa kuhifadhi picha: ",
+		"User created but failed to save profile image: ":        "Mtumiaji ameundwa lakini imeshindwa kuhifadhi picha ya wasifu: ",
+		"User created but failed to update profile image path: ": "Mtumiaji ameundwa lakini imeshindwa kusasisha njia ya picha ya wasifu: ",
+		"Phone number already verified":                          "Nambari ya simu tayari imethibitishwa",
+		"Failed to verify phone number":                          "Imeshindwa kuthibitisha nambari ya simu",
+		"Failed to create verification code":                     "Imeshindwa kuunda nambari ya uthibitisho",
+		"Failed to send verification code":                       "Imeshindwa kutuma nambari ya uthibitisho",
+		"Too many verification requests, try again later":        "Maombi mengi mno ya uthibitisho, jaribu tena baadaye",
+		"Invalid or expired code":                                "Nambari si sahihi au imeisha muda wake",
+
+		// Passwords
+		"Failed to create reset code": "Imeshindwa kuunda nambari ya kubadilisha nenosiri",
+		"Failed to send reset code":   "Imeshindwa kutuma nambari ya kubadilisha nenosiri",
+		"Failed to reset password":    "Imeshindwa kubadilisha nenosiri",
+
+		// Two-factor authentication
+		"Two-factor authentication is already enabled": "Uthibitishaji wa hatua mbili tayari umewashwa",
+		"Two-factor authentication is not enabled":     "Uthibitishaji wa hatua mbili haujawashwa",
+		"Start two-factor setup first":                 "Anza kwanza kuweka uthibitishaji wa hatua mbili",
+		"Invalid two-factor code":                      "Nambari ya hatua mbili si sahihi",
+		"Invalid or expired challenge token":           "Tokeni ya changamoto si sahihi au imeisha muda wake",
+		"Failed to generate secret":                    "Imeshindwa kutengeneza siri",
+		"Failed to save secret":                        "Imeshindwa kuhifadhi siri",
+		"Failed to enable two-factor authentication":   "Imeshindwa kuwasha uthibitishaji wa hatua mbili",
+		"Failed to disable two-factor authentication":  "Imeshindwa kuzima uthibitishaji wa hatua mbili",
+		"Failed to generate recovery codes":            "Imeshindwa kutengeneza nambari za kurejesha",
+		"Failed to start two-factor login":             "Imeshindwa kuanza kuingia kwa hatua mbili",
+
+		// Posts
+		"Invalid post ID":                             "Kitambulisho cha chapisho si sahihi",
+		"Post not found":                              "Chapisho halikupatikana",
+		"Failed to fetch posts":                       "Imeshindwa kupata machapisho",
+		"Failed to create post: ":                     "Imeshindwa kuunda chapisho: ",
+		"Failed to update post":                       "Imeshindwa kusasisha chapisho",
+		"Failed to delete post":                       "Imeshindwa kufuta chapisho",
+		"Invalid status: must be one of ":             "Hali si sahihi: lazima iwe mojawapo ya ",
+		"Published posts cannot be unpublished":       "Machapisho yaliyochapishwa hayawezi kurudishwa kuwa rasimu",
+		"Scheduled posts need a publishAt time":       "Machapisho yaliyoratibiwa yanahitaji muda wa publishAt",
+		"publishAt must be in the future":             "publishAt lazima iwe wakati ujao",
+		"Post was changed in the meantime, try again": "Chapisho limebadilika wakati wa kusasisha, jaribu tena",
+
+		// Comments
+		"Invalid comment ID":         "Kitambulisho cha maoni si sahihi",
+		"Comment not found":          "Maoni hayakupatikana",
+		"Invalid parent comment":     "Maoni yanayojibiwa si sahihi",
+		"Comment cannot be empty":    "Maoni hayawezi kuwa tupu",
+		"Comment is too long: max ":  "Maoni ni marefu mno: upeo ni ",
+		"Failed to fetch comments":   "Imeshindwa kupata maoni",
+		"Failed to create comment: ": "Imeshindwa kuunda maoni: ",
+		"Failed to update comment":   "Imeshindwa kusasisha maoni",
+		"Failed to delete comment":   "Imeshindwa kufuta maoni",
+
+		// Post images
+		"Too many images: max ":               "Picha ni nyingi mno: upeo ni ",
+		"Image cannot be empty":               "Picha haiwezi kuwa tupu",
+		"Caption is too long: max ":           "Maelezo ya picha ni marefu mno: upeo ni ",
+		"Invalid image ID":                    "Kitambulisho cha picha si sahihi",
+		"Image not found":                     "Picha haikupatikana",
+		"Failed to add image":                 "Imeshindwa kuongeza picha",
+		"Failed to update image":              "Imeshindwa kusasisha picha",
+		"Failed to delete image":              "Imeshindwa kufuta picha",
+		"Failed to reorder images":            "Imeshindwa kupanga upya picha",
+		"Invalid order: list each image once": "Mpangilio si sahihi: orodhesha kila picha mara moja",
+
+		// Reports and moderation
+		"Invalid reason: must be one of ":           "Sababu si sahihi: lazima iwe mojawapo ya ",
+		"Details are too long: max ":                "Maelezo ni marefu mno: upeo ni ",
+		"You cannot report your own content":        "Huwezi kuripoti maudhui yako mwenyewe",
+		"You have already reported this content":    "Tayari umeripoti maudhui haya",
+		"Failed to submit report":                   "Imeshindwa kutuma ripoti",
+		"Invalid type: must be post or comment":     "Aina si sahihi: lazima iwe post au comment",
+		"Failed to fetch reports":                   "Imeshindwa kupata ripoti",
+		"Invalid report ID":                         "Kitambulisho cha ripoti si sahihi",
+		"Report not found":                          "Ripoti haikupatikana",
+		"Invalid action: must be one of ":           "Kitendo si sahihi: lazima kiwe mojawapo ya ",
+		"Reported content not found":                "Maudhui yaliyoripotiwa hayakupatikana",
+		"Reported content has already been deleted": "Maudhui yaliyoripotiwa tayari yamefutwa",
+		"Failed to apply moderation action":         "Imeshindwa kutekeleza kitendo cha usimamizi",
+		"Failed to unsuspend user":                  "Imeshindwa kuondoa usimamishaji wa mtumiaji",
+
+		// Feed
+		"Invalid mode: must be latest or ranked": "Hali ya mpangilio si sahihi: lazima iwe latest au ranked",
+		"Invalid cursor":                         "Kielekezi si sahihi",
+		"Failed to fetch feed":                   "Imeshindwa kupata mlisho",
+
+		// Tags
+		"Invalid days: must be between 1 and ": "Siku si sahihi: lazima ziwe kati ya 1 na ",
+		"Failed to fetch trending tags":        "Imeshindwa kupata lebo zinazovuma",
+
+		// Reactions
+		"Invalid reaction: must be one of ": "Hisia si sahihi: lazima iwe mojawapo ya ",
+		"Failed to react to post":           "Imeshindwa kuweka hisia kwenye chapisho",
+		"Failed to remove reaction":         "Imeshindwa kuondoa hisia",
+
+		// Jobs
+		"Invalid job ID":         "Kitambulisho cha kazi si sahihi",
+		"Job not found":          "Kazi haikupatikana",
+		"Failed to fetch jobs":   "Imeshindwa kupata kazi",
+		"Failed to create job: ": "Imeshindwa kuunda kazi: ",
+		"Failed to update job":   "Imeshindwa kusasisha kazi",
+		"Failed to delete job":   "Imeshindwa kufuta kazi",
+
+		// Events
+		"Invalid event ID":         "Kitambulisho cha tukio si sahihi",
+		"Event not found":          "Tukio halikupatikana",
+		"Failed to fetch events":   "Imeshindwa kupata matukio",
+		"Failed to create event: ": "Imeshindwa kuunda tukio: ",
+		"Failed to update event":   "Imeshindwa kusasisha tukio",
+		"Failed to delete event":   "Imeshindwa kufuta tukio",
+		"Failed to follow event":   "Imeshindwa kufuatilia tukio",
+		"Failed to unfollow event": "Imeshindwa kuacha kufuatilia tukio",
+
+		// Timetables
+		"Invalid timetable ID":         "Kitambulisho cha ratiba si sahihi",
+		"Timetable not found":          "Ratiba haikupatikana",
+		"Failed to fetch timetables":   "Imeshindwa kupata ratiba",
+		"Failed to create timetable: ": "Imeshindwa kuunda ratiba: ",
+		"Failed to update timetable":   "Imeshindwa kusasisha ratiba",
+		"Failed to delete timetable":   "Imeshindwa kufuta ratiba",
+
+		// Notifications
+		"Invalid notification ID":                   "Kitambulisho cha arifa si sahihi",
+		"Notification not found":                    "Arifa haikupatikana",
+		"Failed to fetch notifications":             "Imeshindwa kupata arifa",
+		"Failed to update notification":             "Imeshindwa kusasisha arifa",
+		"Failed to update notifications":            "Imeshindwa kusasisha arifa",
+		"Failed to fetch notification preferences":  "Imeshindwa kupata mapendeleo ya arifa",
+		"Failed to update notification preferences": "Imeshindwa kusasisha mapendeleo ya arifa",
+
+		// Outbox, broadcasts and the SMS log
+		"Invalid outbox message ID":                                 "Kitambulisho cha ujumbe unaotoka si sahihi",
+		"Outbox message not found":                                  "Ujumbe unaotoka haukupatikana",
+		"Failed to fetch outbox messages":                           "Imeshindwa kupata jumbe zinazotoka",
+		"Failed to retry outbox message":                            "Imeshindwa kujaribu tena ujumbe unaotoka",
+		"Only failed or dead messages can be retried":               "Jumbe zilizoshindwa au zilizokufa pekee ndizo zinaweza kujaribiwa tena",
+		"The code in this message was discarded; request a new one": "Msimbo ulio katika ujumbe huu umefutwa; omba mpya",
+		"Invalid broadcast ID":                                      "Kitambulisho cha tangazo si sahihi",
+		"Broadcast not found":                                       "Tangazo halikupatikana",
+		"Message cannot be empty":                                   "Ujumbe hauwezi kuwa tupu",
+		"No verified recipients match the filter":                   "Hakuna wapokeaji waliothibitishwa wanaolingana na kichujio",
+		"Failed to count recipients":                                "Imeshindwa kuhesabu wapokeaji",
+		"Failed to load recipients":                                 "Imeshindwa kupakia wapokeaji",
+		"Failed to fetch recipients":                                "Imeshindwa kupata wapokeaji",
+		"Failed to create broadcast: ":                              "Imeshindwa kuunda tangazo: ",
+		"Failed to fetch broadcasts":                                "Imeshindwa kupata matangazo",
+		"Failed to fetch SMS messages":                              "Imeshindwa kupata jumbe za SMS",
+		"SMS message not found":                                     "Ujumbe wa SMS haukupatikana",
+		"SMS webhook is not configured":                             "Webhook ya SMS haijasanidiwa",
+		"Invalid webhook secret":                                    "Siri ya webhook si sahihi",
+		"Failed to record delivery report":                          "Imeshindwa kurekodi ripoti ya uwasilishaji",
+	},
+}
@@ -0,0 +1,157 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Message template keys for outbound SMS and notifications. Notification
+// templates have a ".title" and a ".body" variant.
+const (
+	MessageRegistrationSMS      = "sms.registration"
+	MessagePhoneVerificationSMS = "sms.phone_verification"
+	MessagePasswordResetSMS     = "sms.password_reset"
+	MessageNotificationSMS      = "sms.notification"
+
+	MessageEventUpdated     = "notification.event_updated"
+	MessageEventCancelled   = "notification.event_cancelled"
+	MessageEventReminder    = "notification.event_reminder"
+	MessageTimetableChanged = "notification.timetable_changed"
+	MessageCommentAdded     = "notification.comment_added"
+	MessageCommentReply     = "notification.comment_reply"
+	MessageMentioned        = "notification.mentioned"
+	MessageJobPosted        = "notification.job_posted"
+
+	MessageContentHidden     = "notification.content_hidden"
+	MessageContentRemoved    = "notification.content_removed"
+	MessageModerationWarning = "notification.moderation_warning"
+	MessageAccountSuspended  = "notification.account_suspended"
+)
+
+// messageCatalog holds every message template per locale, as text/template source
+var messageCatalog = map[string]map[string]string{
+	LocaleEnglish: {
+		MessageRegistrationSMS:      "Hello {{.Name}}, welcome to Campus Connect! Your verification code is {{.Code}}.",
+		MessagePhoneVerificationSMS: "Your Campus Connect verification code is {{.Code}}.",
+		MessagePasswordResetSMS:     "Your Campus Connect password reset code is {{.Code}}. It expires in {{.Minutes}} minutes.",
+		MessageNotificationSMS:      "{{.Heading}}: {{.Message}}",
+
+		MessageEventUpdated + ".title":     "Event updated",
+		MessageEventUpdated + ".body":      "{{.Title}} is now on {{datetime .Date}}",
+		MessageEventCancelled + ".title":   "Event cancelled",
+		MessageEventCancelled + ".body":    "{{.Title}} on {{date .Date}} has been cancelled",
+		MessageEventReminder + ".title":    "Event reminder",
+		MessageEventReminder + ".body":     "{{.Title}} starts on {{datetime .Date}}",
+		MessageTimetableChanged + ".title": "Timetable change",
+		MessageTimetableChanged + ".body":  "{{.Subject}} ({{.SubjectCode}}) is now on {{weekday .Day}} at {{clock .Time}} in {{.Room}}",
+		MessageCommentAdded + ".title":     "New comment",
+		MessageCommentAdded + ".body":      "{{.Name}} commented on your post \"{{.Title}}\"",
+		MessageCommentReply + ".title":     "New reply",
+		MessageCommentReply + ".body":      "{{.Name}} replied to your comment on \"{{.Title}}\"",
+		MessageMentioned + ".title":        "You were mentioned",
+		MessageMentioned + ".body":         "{{.Name}} mentioned you in \"{{.Title}}\"",
+		MessageJobPosted + ".title":        "New job",
+		MessageJobPosted + ".body":         "{{.Company}} is hiring: {{.Title}}",
+
+		MessageContentHidden + ".title":     "Content hidden",
+		MessageContentHidden + ".body":      "A moderator hid \"{{.Title}}\" after it was reported.{{if .Note}} {{.Note}}{{end}}",
+		MessageContentRemoved + ".title":    "Content removed",
+		MessageContentRemoved + ".body":     "A moderator removed \"{{.Title}}\" after it was reported.{{if .Note}} {{.Note}}{{end}}",
+		MessageModerationWarning + ".title": "Warning from a moderator",
+		MessageModerationWarning + ".body":  "\"{{.Title}}\" breaks the community rules. Further reports may get your account suspended.{{if .Note}} {{.Note}}{{end}}",
+		MessageAccountSuspended + ".title":  "Account suspended",
+		MessageAccountSuspended + ".body":   "Your account is suspended until {{datetime .Until}} because of \"{{.Title}}\".{{if .Note}} {{.Note}}{{end}}",
+	},
+	LocaleSwahili: {
+		MessageRegistrationSMS:      "Habari {{.Name}}, karibu Campus Connect! Nambari yako ya uthibitisho ni {{.Code}}.",
+		MessagePhoneVerificationSMS: "Nambari yako ya uthibitisho ya Campus Connect ni {{.Code}}.",
+		MessagePasswordResetSMS:     "Nambari yako ya kubadilisha nenosiri la Campus Connect ni {{.Code}}. Itaisha muda baada ya dakika {{.Minutes}}.",
+		MessageNotificationSMS:      "{{.Heading}}: {{.Message}}",
+
+		MessageEventUpdated + ".title":     "Tukio limebadilishwa",
+		MessageEventUpdated + ".body":      "{{.Title}} sasa litafanyika {{datetime .Date}}",
+		MessageEventCancelled + ".title":   "Tukio limeghairiwa",
+		MessageEventCancelled + ".body":    "{{.Title}} la {{date .Date}} limeghairiwa",
+		MessageEventReminder + ".title":    "Kikumbusho cha tukio",
+		MessageEventReminder + ".body":     "{{.Title}} litaanza {{datetime .Date}}",
+		MessageTimetableChanged + ".title": "Mabadiliko ya ratiba",
+		MessageTimetableChanged + ".body":  "{{.Subject}} ({{.SubjectCode}}) sasa ni {{weekday .Day}} saa {{clock .Time}} katika {{.Room}}",
+		MessageCommentAdded + ".title":     "Maoni mapya",
+		MessageCommentAdded + ".body":      "{{.Name}} ametoa maoni kwenye chapisho lako \"{{.Title}}\"",
+		MessageCommentReply + ".title":     "Jibu jipya",
+		MessageCommentReply + ".body":      "{{.Name}} amejibu maoni yako kwenye \"{{.Title}}\"",
+		MessageMentioned + ".title":        "Umetajwa",
+		MessageMentioned + ".body":         "{{.Name}} amekutaja kwenye \"{{.Title}}\"",
+		MessageJobPosted + ".title":        "Kazi mpya",
+		MessageJobPosted + ".body":         "{{.Company}} inaajiri: {{.Title}}",
+
+		MessageContentHidden + ".title":     "Maudhui yamefichwa",
+		MessageContentHidden + ".body":      "Msimamizi wa maudhui ameficha \"{{.Title}}\" baada ya kuripotiwa.{{if .Note}} {{.Note}}{{end}}",
+		MessageContentRemoved + ".title":    "Maudhui yameondolewa",
+		MessageContentRemoved + ".body":     "Msimamizi wa maudhui ameondoa \"{{.Title}}\" baada ya kuripotiwa.{{if .Note}} {{.Note}}{{end}}",
+		MessageModerationWarning + ".title": "Onyo kutoka kwa msimamizi wa maudhui",
+		MessageModerationWarning + ".body":  "\"{{.Title}}\" inakiuka kanuni za jumuiya. Ripoti zaidi zinaweza kusababisha akaunti yako kusimamishwa.{{if .Note}} {{.Note}}{{end}}",
+		MessageAccountSuspended + ".title":  "Akaunti imesimamishwa",
+		MessageAccountSuspended + ".body":   "Akaunti yako imesimamishwa hadi {{datetime .Until}} kwa sababu ya \"{{.Title}}\".{{if .Note}} {{.Note}}{{end}}",
+	},
+}
+
+// templateFuncs are available in message and email templates, formatting for the locale
+func templateFuncs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"date":     func(t time.Time) string { return FormatDate(locale, t, false) },
+		"datetime": func(t time.Time) string { return FormatDate(locale, t, true) },
+		"clock":    func(t time.Time) string { return t.Format("15:04") },
+		"weekday":  func(day string) string { return LocalWeekday(locale, day) },
+	}
+}
+
+var (
+	messageTemplatesOnce sync.Once
+	messageTemplates     map[string]*template.Template
+	messageTemplatesErr  error
+)
+
+// parseMessageTemplates parses the catalog once, one template set per locale
+func parseMessageTemplates() (map[string]*template.Template, error) {
+	messageTemplatesOnce.Do(func() {
+		messageTemplates = map[string]*template.Template{}
+		for locale, messages := range messageCatalog {
+			set := template.New(locale).Funcs(templateFuncs(locale))
+			for key, source := range messages {
+				if _, err := set.New(key).Parse(source); err != nil {
+					messageTemplatesErr = fmt.Errorf("message %q (%s): %v", key, locale, err)
+					return
+				}
+			}
+			messageTemplates[locale] = set
+		}
+	})
+	return messageTemplates, messageTemplatesErr
+}
+
+// RenderMessage fills the message template key in the locale, falling back to
+// English when the locale has no variant
+func RenderMessage(locale, key string, data map[string]interface{}) (string, error) {
+	sets, err := parseMessageTemplates()
+	if err != nil {
+		return "", err
+	}
+	set := sets[locale]
+	if set == nil || set.Lookup(key) == nil {
+		set = sets[DefaultLocale]
+	}
+	if set.Lookup(key) == nil {
+		return "", fmt.Errorf("unknown message template %q", key)
+	}
+
+	var out bytes.Buffer
+	if err := set.ExecuteTemplate(&out, key, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+// OTPTTL reads OTP_TTL (e.g. "10m") and falls back to 10 minutes
+func OTPTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("OTP_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 10 * time.Minute
+}
+
+// GenerateOTP returns a random numeric code with the given number of digits
+func GenerateOTP(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code: %v", err)
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
@@ -0,0 +1,60 @@
+package helpers
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// ErrInvalidPhone is returned for numbers that cannot be turned into E.164
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// DefaultCountryCode is the calling code assumed for national numbers, from
+// DEFAULT_COUNTRY_CODE (default 255, Tanzania)
+func DefaultCountryCode() string {
+	if code := strings.TrimPrefix(strings.TrimSpace(os.Getenv("DEFAULT_COUNTRY_CODE")), "+"); code != "" {
+		return code
+	}
+	return "255"
+}
+
+// NormalizePhone converts a phone number to E.164, e.g. "+255787504956".
+// Spaces, dashes, dots and brackets are ignored. Numbers starting with "+" or
+// "00" are international; numbers starting with a single "0" are national
+// and get the default country code in place of the 0. Numbers without either
+// prefix get the default country code unless they already start with it.
+func NormalizePhone(raw string) (string, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')', '\t':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(raw))
+
+	countryCode := DefaultCountryCode()
+	var digits string
+	switch {
+	case strings.HasPrefix(cleaned, "+"):
+		digits = cleaned[1:]
+	case strings.HasPrefix(cleaned, "00"):
+		digits = cleaned[2:]
+	case strings.HasPrefix(cleaned, "0"):
+		digits = countryCode + cleaned[1:]
+	case strings.HasPrefix(cleaned, countryCode):
+		digits = cleaned
+	default:
+		digits = countryCode + cleaned
+	}
+
+	// E.164 allows at most 15 digits and country codes never start with 0
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidPhone
+		}
+	}
+	return "+" + digits, nil
+}
@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SmsSender delivers a text message to one or more phone numbers. Name
+// identifies the provider in the message log.
+type SmsSender interface {
+	Name() string
+	Send(message string, recipients []string) ([]SmsReceipt, error)
+}
+
+// SmsReceipt is the provider's reference for the message sent to one recipient.
+// MessageID is empty when the provider does not return one. Error is set when
+// the message to this recipient alone was not sent, e.g. for an invalid number.
+type SmsReceipt struct {
+	Recipient string
+	MessageID string
+	Error     string
+}
+
+// SensitiveSmsSender is implemented by senders that must not keep the text of
+// messages carrying one-time codes, such as the SMS log
+type SensitiveSmsSender interface {
+	SendSensitive(message string, recipients []string) ([]SmsReceipt, error)
+}
+
+// SmsBatchSizer is implemented by senders that limit how many recipients one request may carry
+type SmsBatchSizer interface {
+	MaxBatchSize() int
+}
+
+// SMS is the sender used by SendSMS and SendBatchSMS. It is set at startup by initializers.ConfigureSMS
+// and wrapped by notifications.EnableSmsLog.
+var SMS SmsSender
+
+// SendSMS sends a text message to the given phone numbers through the configured
+// provider. It fails if the message could not be sent to any one of them.
+func SendSMS(message string, recipients []string) error {
+	return receiptsError(SendBatchSMS(message, recipients))
+}
+
+// SendSensitiveSMS is SendSMS for messages carrying one-time codes
+func SendSensitiveSMS(message string, recipients []string) error {
+	if SMS == nil {
+		return errors.New("SMS sender is not configured")
+	}
+	if sender, ok := SMS.(SensitiveSmsSender); ok {
+		return receiptsError(sender.SendSensitive(message, recipients))
+	}
+	return receiptsError(SMS.Send(message, recipients))
+}
+
+// receiptsError returns err, or an error for the first recipient that failed
+func receiptsError(receipts []SmsReceipt, err error) error {
+	if err != nil {
+		return err
+	}
+	for _, receipt := range receipts {
+		if receipt.Error != "" {
+			return fmt.Errorf("failed to send SMS to %s: %s", receipt.Recipient, receipt.Error)
+		}
+	}
+	return nil
+}
+
+// SendBatchSMS sends a text message to the given phone numbers through the
+// configured provider and returns a receipt for each, in recipient order.
+// Recipients the provider rejected one by one have an Error on their receipt.
+func SendBatchSMS(message string, recipients []string) ([]SmsReceipt, error) {
+	if SMS == nil {
+		return nil, errors.New("SMS sender is not configured")
+	}
+	return SMS.Send(message, recipients)
+}
+
+// SmsBatchSize returns how many recipients to send per request with the configured sender
+func SmsBatchSize() int {
+	if sizer, ok := SMS.(SmsBatchSizer); ok && sizer.MaxBatchSize() > 0 {
+		return sizer.MaxBatchSize()
+	}
+	return 100
+}
+
+// NewSmsSenderFromEnv builds the sender selected by SMS_PROVIDER:
+// "huduma" for the Huduma SMS API, "file" to append messages to SMS_FILE_PATH
+// for local development, or "memory" to keep them in memory for tests. It has no
+// default: the local senders keep codes in plaintext, so they must be chosen.
+func NewSmsSenderFromEnv() (SmsSender, error) {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("SMS_PROVIDER")))
+	switch provider {
+	case "":
+		return nil, errors.New("SMS_PROVIDER is not set; choose huduma, file or memory")
+	case "huduma":
+		return NewHudumaSenderFromEnv()
+	case "file":
+		path := os.Getenv("SMS_FILE_PATH")
+		if path == "" {
+			path = "./tmp/sms.log"
+		}
+		return &FileSmsSender{Path: path, Reports: deliveryReporterFromEnv()}, nil
+	case "memory":
+		return &MemorySmsSender{Reports: deliveryReporterFromEnv()}, nil
+	default:
+		return nil, fmt.Errorf("unknown SMS_PROVIDER %q", provider)
+	}
+}
@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SentSms is a message captured by one of the local senders
+type SentSms struct {
+	SentAt     time.Time    `json:"sentAt"`
+	Message    string       `json:"message"`
+	Recipients []string     `json:"recipients"`
+	Receipts   []SmsReceipt `json:"receipts"`
+}
+
+// fakeReceipts gives each recipient a random message ID, as a real provider would
+func fakeReceipts(prefix string, recipients []string) []SmsReceipt {
+	receipts := make([]SmsReceipt, len(recipients))
+	for i, recipient := range recipients {
+		bytes := make([]byte, 8)
+		rand.Read(bytes)
+		receipts[i] = SmsReceipt{Recipient: recipient, MessageID: prefix + "-" + hex.EncodeToString(bytes)}
+	}
+	return receipts
+}
+
+// FileSmsSender appends each message as a JSON line to Path instead of sending it.
+// Use it in development to read OTPs without a real provider. Set Reports to
+// have it post delivery reports back to the webhook.
+type FileSmsSender struct {
+	Path    string
+	Reports DeliveryReporter
+	mu      sync.Mutex
+}
+
+func (f *FileSmsSender) Name() string {
+	return "file"
+}
+
+func (f *FileSmsSender) Send(message string, recipients []string) ([]SmsReceipt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create SMS log directory: %v", err)
+	}
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SMS log: %v", err)
+	}
+	defer file.Close()
+
+	receipts := fakeReceipts(f.Name(), recipients)
+	line, _ := json.Marshal(SentSms{SentAt: time.Now(), Message: message, Recipients: recipients, Receipts: receipts})
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write SMS log: %v", err)
+	}
+	f.Reports.report(f.Name(), receipts)
+	return receipts, nil
+}
+
+// MemorySmsSender records messages in memory so tests can assert on them.
+// Set Err to make every send fail, and Reports to have it post delivery
+// reports back to the webhook.
+type MemorySmsSender struct {
+	Err     error
+	Reports DeliveryReporter
+	mu      sync.Mutex
+	sent    []SentSms
+}
+
+func (m *MemorySmsSender) Name() string {
+	return "memory"
+}
+
+func (m *MemorySmsSender) Send(message string, recipients []string) ([]SmsReceipt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	receipts := fakeReceipts(m.Name(), recipients)
+	m.sent = append(m.sent, SentSms{SentAt: time.Now(), Message: message, Recipients: recipients, Receipts: receipts})
+	m.Reports.report(m.Name(), receipts)
+	return receipts, nil
+}
+
+// Sent returns a copy of every message recorded so far
+func (m *MemorySmsSender) Sent() []SentSms {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SentSms(nil), m.sent...)
+}
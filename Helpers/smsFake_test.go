@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMemorySmsSenderRecordsMessages(t *testing.T) {
+	sender := &MemorySmsSender{}
+
+	receipts, err := sender.Send("hello", []string{"255700000001", "255700000002"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(receipts) != 2 {
+		t.Fatalf("got %d receipts, want 2", len(receipts))
+	}
+	for i, recipient := range []string{"255700000001", "255700000002"} {
+		if receipts[i].Recipient != recipient {
+			t.Errorf("receipt %d is for %q, want %q", i, receipts[i].Recipient, recipient)
+		}
+		if !strings.HasPrefix(receipts[i].MessageID, "memory-") {
+			t.Errorf("receipt %d has message ID %q, want a memory- prefix", i, receipts[i].MessageID)
+		}
+	}
+	if receipts[0].MessageID == receipts[1].MessageID {
+		t.Errorf("both receipts share message ID %q", receipts[0].MessageID)
+	}
+
+	sent := sender.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("got %d recorded messages, want 1", len(sent))
+	}
+	if sent[0].Message != "hello" || len(sent[0].Recipients) != 2 {
+		t.Errorf("recorded %+v, want the message to both recipients", sent[0])
+	}
+}
+
+func TestMemorySmsSenderErr(t *testing.T) {
+	want := errors.New("provider down")
+	sender := &MemorySmsSender{Err: want}
+
+	if _, err := sender.Send("hello", []string{"255700000001"}); !errors.Is(err, want) {
+		t.Fatalf("Send returned %v, want %v", err, want)
+	}
+	if sent := sender.Sent(); len(sent) != 0 {
+		t.Errorf("recorded %d messages after a failed send, want 0", len(sent))
+	}
+}
+
+func TestFileSmsSenderAppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sms", "sms.log")
+	sender := &FileSmsSender{Path: path}
+
+	for _, message := range []string{"first", "second"} {
+		receipts, err := sender.Send(message, []string{"255700000001"})
+		if err != nil {
+			t.Fatalf("Send(%q): %v", message, err)
+		}
+		if len(receipts) != 1 || !strings.HasPrefix(receipts[0].MessageID, "file-") {
+			t.Fatalf("Send(%q) returned receipts %+v", message, receipts)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open SMS log: %v", err)
+	}
+	defer file.Close()
+
+	var lines []SentSms
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line SentSms
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("SMS log line %q is not JSON: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines in the SMS log, want 2", len(lines))
+	}
+	if lines[0].Message != "first" || lines[1].Message != "second" {
+		t.Errorf("logged messages %q and %q, want first and second", lines[0].Message, lines[1].Message)
+	}
+	if len(lines[0].Receipts) != 1 || lines[0].Receipts[0].Recipient != "255700000001" {
+		t.Errorf("logged receipts %+v, want one for the recipient", lines[0].Receipts)
+	}
+}
@@ -0,0 +1,276 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SmsRecipient struct {
+	Number int64 `json:"number"`
+}
+
+type SmsRequest struct {
+	SenderID   int            `json:"sender_id"`
+	SMS        string         `json:"sms"`
+	Schedule   string         `json:"schedule"`
+	Recipients []SmsRecipient `json:"recipients"`
+}
+
+// HudumaSender sends SMS through the Huduma SMS API. The auth token is cached
+// and reused until shortly before it expires.
+type HudumaSender struct {
+	BaseURL   string
+	UserID    int64
+	Password  string
+	SenderID  int
+	TokenTTL  time.Duration
+	BatchSize int
+	Client    *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewHudumaSenderFromEnv reads HUDUMA_BASE_URL, HUDUMA_USER_ID, HUDUMA_PASSWORD,
+// HUDUMA_SENDER_ID and the optional HUDUMA_TOKEN_TTL and HUDUMA_BATCH_SIZE
+func NewHudumaSenderFromEnv() (*HudumaSender, error) {
+	baseURL := strings.TrimRight(os.Getenv("HUDUMA_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = "https://dev.hudumasms.com/api"
+	}
+
+	userID, err := strconv.ParseInt(os.Getenv("HUDUMA_USER_ID"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("HUDUMA_USER_ID must be a number: %v", err)
+	}
+
+	password := os.Getenv("HUDUMA_PASSWORD")
+	if password == "" {
+		return nil, errors.New("HUDUMA_PASSWORD is not set")
+	}
+
+	senderID, err := strconv.Atoi(os.Getenv("HUDUMA_SENDER_ID"))
+	if err != nil {
+		return nil, fmt.Errorf("HUDUMA_SENDER_ID must be a number: %v", err)
+	}
+
+	tokenTTL := 50 * time.Minute
+	if ttl, err := time.ParseDuration(os.Getenv("HUDUMA_TOKEN_TTL")); err == nil && ttl > 0 {
+		tokenTTL = ttl
+	}
+
+	batchSize := 100
+	if size, err := strconv.Atoi(os.Getenv("HUDUMA_BATCH_SIZE")); err == nil && size > 0 {
+		batchSize = size
+	}
+
+	return &HudumaSender{
+		BaseURL:   baseURL,
+		UserID:    userID,
+		Password:  password,
+		SenderID:  senderID,
+		TokenTTL:  tokenTTL,
+		BatchSize: batchSize,
+		Client:    &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// Name identifies Huduma in the SMS log
+func (h *HudumaSender) Name() string {
+	return "huduma"
+}
+
+// MaxBatchSize is the most recipients sent in one send-sms request
+func (h *HudumaSender) MaxBatchSize() int {
+	return h.BatchSize
+}
+
+// Send delivers the message, fetching a new token only when the cached one has
+// expired or the API rejects it. Numbers that cannot be parsed fail on their
+// own receipts and the message still goes to the rest.
+func (h *HudumaSender) Send(message string, recipients []string) ([]SmsReceipt, error) {
+	receipts := make([]SmsReceipt, len(recipients))
+	var formattedRecipients []SmsRecipient
+	var sentTo []int
+	for i, rec := range recipients {
+		receipts[i] = SmsReceipt{Recipient: rec}
+		number, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(rec), "+"), 10, 64)
+		if err != nil {
+			receipts[i].Error = "invalid phone number"
+			continue
+		}
+		formattedRecipients = append(formattedRecipients, SmsRecipient{Number: number})
+		sentTo = append(sentTo, i)
+	}
+	if len(formattedRecipients) == 0 {
+		return receipts, nil
+	}
+
+	smsJson, _ := json.Marshal(SmsRequest{
+		SenderID:   h.SenderID,
+		SMS:        message,
+		Schedule:   "None",
+		Recipients: formattedRecipients,
+	})
+
+	token, err := h.authToken()
+	if err != nil {
+		return nil, err
+	}
+
+	status, body, err := h.postSMS(token, smsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cached token may have been revoked early; retry once with a fresh one
+	if status == http.StatusUnauthorized {
+		h.clearToken()
+		if token, err = h.authToken(); err != nil {
+			return nil, err
+		}
+		if status, body, err = h.postSMS(token, smsJson); err != nil {
+			return nil, err
+		}
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("SMS API returned non-200 status: %d, %s", status, body)
+	}
+
+	validRecipients := make([]string, len(sentTo))
+	for j, i := range sentTo {
+		validRecipients[j] = recipients[i]
+	}
+	for j, receipt := range hudumaReceipts(body, validRecipients) {
+		receipts[sentTo[j]] = receipt
+	}
+	return receipts, nil
+}
+
+// hudumaReceipts picks message IDs out of a send response. The API returns
+// either one object or one entry per recipient under "data"; recipients
+// without an ID get an empty one.
+func hudumaReceipts(body string, recipients []string) []SmsReceipt {
+	messageID := func(m map[string]json.RawMessage) string {
+		for _, key := range []string{"message_id", "messageId", "id"} {
+			if id, ok := m[key]; ok && string(id) != "null" {
+				return strings.Trim(string(id), `"`)
+			}
+		}
+		return ""
+	}
+
+	var response struct {
+		Data json.RawMessage `json:"data"`
+	}
+	json.Unmarshal([]byte(body), &response)
+
+	var ids []string
+	var list []map[string]json.RawMessage
+	var single map[string]json.RawMessage
+	if json.Unmarshal(response.Data, &list) == nil {
+		for _, m := range list {
+			ids = append(ids, messageID(m))
+		}
+	} else if json.Unmarshal(response.Data, &single) == nil && len(recipients) == 1 {
+		ids = []string{messageID(single)}
+	}
+
+	receipts := make([]SmsReceipt, len(recipients))
+	for i, recipient := range recipients {
+		receipts[i] = SmsReceipt{Recipient: recipient}
+		if len(ids) == len(recipients) {
+			receipts[i].MessageID = ids[i]
+		}
+	}
+	return receipts
+}
+
+func (h *HudumaSender) postSMS(token string, payload []byte) (int, string, error) {
+	req, _ := http.NewRequest("POST", h.BaseURL+"/send-sms", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to send SMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(respBody), nil
+}
+
+// authToken returns the cached token or authenticates for a new one
+func (h *HudumaSender) authToken() (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.token != "" && time.Now().Before(h.tokenExpiry) {
+		return h.token, nil
+	}
+
+	authJson, _ := json.Marshal(map[string]interface{}{
+		"user_id":  h.UserID,
+		"password": h.Password,
+	})
+
+	authResp, err := h.Client.Post(h.BaseURL+"/create-token", "application/json", bytes.NewBuffer(authJson))
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate to SMS API: %w", err)
+	}
+	defer authResp.Body.Close()
+
+	var authResult struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	bodyBytes, _ := io.ReadAll(authResp.Body)
+	if err := json.Unmarshal(bodyBytes, &authResult); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if authResult.Data.Token == "" {
+		return "", errors.New("token not received from SMS API")
+	}
+
+	h.token = authResult.Data.Token
+	h.tokenExpiry = tokenExpiry(h.token, h.TokenTTL)
+	return h.token, nil
+}
+
+func (h *HudumaSender) clearToken() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.token = ""
+}
+
+// tokenExpiry uses the exp claim when the provider token is a JWT, with a
+// minute of margin, and otherwise falls back to ttl from now
+func tokenExpiry(token string, ttl time.Duration) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) == 3 {
+		if payload, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+			var claims struct {
+				Exp int64 `json:"exp"`
+			}
+			if json.Unmarshal(payload, &claims) == nil && claims.Exp > 0 {
+				return time.Unix(claims.Exp, 0).Add(-time.Minute)
+			}
+		}
+	}
+	return time.Now().Add(ttl)
+}
@@ -0,0 +1,179 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeHuduma imitates the token and send endpoints of the Huduma SMS API
+type fakeHuduma struct {
+	mu          sync.Mutex
+	tokens      int
+	sends       int
+	rejectToken string // send-sms answers 401 for this token
+}
+
+func (f *fakeHuduma) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/create-token":
+		f.tokens++
+		fmt.Fprintf(w, `{"data":{"token":"token-%d"}}`, f.tokens)
+	case "/send-sms":
+		if r.Header.Get("Authorization") == "Bearer "+f.rejectToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var request SmsRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		f.sends++
+		var data []map[string]string
+		for i := range request.Recipients {
+			data = append(data, map[string]string{"message_id": fmt.Sprintf("msg-%d-%d", f.sends, i)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeHuduma) counts() (tokens, sends int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tokens, f.sends
+}
+
+func newTestHudumaSender(t *testing.T, api *fakeHuduma) *HudumaSender {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return &HudumaSender{
+		BaseURL:  server.URL,
+		UserID:   1,
+		Password: "secret",
+		SenderID: 1,
+		TokenTTL: time.Hour,
+		Client:   server.Client(),
+	}
+}
+
+func TestHudumaSenderReusesToken(t *testing.T) {
+	api := &fakeHuduma{}
+	sender := newTestHudumaSender(t, api)
+
+	for i := 0; i < 3; i++ {
+		if _, err := sender.Send("hello", []string{"+255700000001"}); err != nil {
+			t.Fatalf("Send %d: %v", i, err)
+		}
+	}
+	if tokens, sends := api.counts(); tokens != 1 || sends != 3 {
+		t.Errorf("got %d token requests and %d sends, want 1 and 3", tokens, sends)
+	}
+}
+
+func TestHudumaSenderRefreshesExpiredToken(t *testing.T) {
+	api := &fakeHuduma{}
+	sender := newTestHudumaSender(t, api)
+
+	if _, err := sender.Send("hello", []string{"255700000001"}); err != nil {
+		t.Fatalf("first Send: %v", err)
+	}
+	sender.mu.Lock()
+	sender.tokenExpiry = time.Now().Add(-time.Second)
+	sender.mu.Unlock()
+	if _, err := sender.Send("hello", []string{"255700000001"}); err != nil {
+		t.Fatalf("second Send: %v", err)
+	}
+
+	if tokens, _ := api.counts(); tokens != 2 {
+		t.Errorf("got %d token requests, want 2", tokens)
+	}
+}
+
+func TestHudumaSenderRetriesWithFreshTokenOn401(t *testing.T) {
+	api := &fakeHuduma{rejectToken: "token-1"}
+	sender := newTestHudumaSender(t, api)
+
+	receipts, err := sender.Send("hello", []string{"255700000001", "255700000002"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if tokens, sends := api.counts(); tokens != 2 || sends != 1 {
+		t.Errorf("got %d token requests and %d sends, want 2 and 1", tokens, sends)
+	}
+	if len(receipts) != 2 || receipts[0].MessageID != "msg-1-0" || receipts[1].MessageID != "msg-1-1" {
+		t.Errorf("got receipts %+v, want one message ID per recipient", receipts)
+	}
+
+	// The fresh token is cached for the next send
+	if _, err := sender.Send("hello", []string{"255700000001"}); err != nil {
+		t.Fatalf("second Send: %v", err)
+	}
+	if tokens, _ := api.counts(); tokens != 2 {
+		t.Errorf("got %d token requests after the second send, want 2", tokens)
+	}
+}
+
+func TestHudumaSenderSkipsInvalidNumbers(t *testing.T) {
+	api := &fakeHuduma{}
+	sender := newTestHudumaSender(t, api)
+
+	receipts, err := sender.Send("hello", []string{"255700000001", "not-a-number", "+255700000002"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if _, sends := api.counts(); sends != 1 {
+		t.Errorf("got %d sends, want 1", sends)
+	}
+	if len(receipts) != 3 {
+		t.Fatalf("got %d receipts, want 3", len(receipts))
+	}
+	if receipts[0].MessageID != "msg-1-0" || receipts[0].Error != "" {
+		t.Errorf("first receipt is %+v, want it sent as msg-1-0", receipts[0])
+	}
+	if receipts[1].Recipient != "not-a-number" || receipts[1].Error == "" || receipts[1].MessageID != "" {
+		t.Errorf("second receipt is %+v, want it failed", receipts[1])
+	}
+	if receipts[2].MessageID != "msg-1-1" || receipts[2].Error != "" {
+		t.Errorf("third receipt is %+v, want it sent as msg-1-1", receipts[2])
+	}
+}
+
+func TestHudumaSenderAllInvalidNumbers(t *testing.T) {
+	api := &fakeHuduma{}
+	sender := newTestHudumaSender(t, api)
+
+	receipts, err := sender.Send("hello", []string{"nope"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(receipts) != 1 || receipts[0].Error == "" {
+		t.Errorf("got receipts %+v, want one failed receipt", receipts)
+	}
+	if tokens, sends := api.counts(); tokens != 0 || sends != 0 {
+		t.Errorf("got %d token requests and %d sends, want none", tokens, sends)
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	payload, _ := json.Marshal(map[string]int64{"exp": exp.Unix()})
+	jwt := "header." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+
+	if got, want := tokenExpiry(jwt, time.Hour), exp.Add(-time.Minute); !got.Equal(want) {
+		t.Errorf("tokenExpiry(jwt) = %v, want %v", got, want)
+	}
+
+	before := time.Now()
+	got := tokenExpiry("opaque-token", time.Hour)
+	if got.Before(before.Add(time.Hour)) || got.After(time.Now().Add(time.Hour)) {
+		t.Errorf("tokenExpiry(opaque) = %v, want an hour from now", got)
+	}
+}
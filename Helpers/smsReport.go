@@ -0,0 +1,121 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// SmsWebhookSecretHeader carries SMS_WEBHOOK_SECRET on delivery report requests
+const SmsWebhookSecretHeader = "X-Webhook-Secret"
+
+// placeholderWebhookSecrets are values that have been published, in .env.example
+// or in the repository's history, and so cannot authenticate a provider
+var placeholderWebhookSecrets = []string{"change-me", "change-me-sms-webhook-secret"}
+
+// SmsWebhookSecret returns SMS_WEBHOOK_SECRET, or "" while it is unset or
+// still a placeholder, in which case the webhook accepts no reports
+func SmsWebhookSecret() string {
+	secret := os.Getenv("SMS_WEBHOOK_SECRET")
+	for _, placeholder := range placeholderWebhookSecrets {
+		if secret == placeholder {
+			return ""
+		}
+	}
+	return secret
+}
+
+// SmsDeliveryReport is the body accepted by the SMS delivery report webhook.
+// Status is one of sent, delivered or failed; common provider codes such as
+// DELIVRD and UNDELIV are also understood.
+type SmsDeliveryReport struct {
+	Provider  string     `json:"provider"`
+	MessageID string     `json:"messageId"`
+	Recipient string     `json:"recipient"`
+	Status    string     `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// PostDeliveryReport sends a delivery report to the webhook at url the way a provider would
+func PostDeliveryReport(url, secret string, report SmsDeliveryReport) error {
+	body, _ := json.Marshal(report)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SmsWebhookSecretHeader, secret)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post delivery report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delivery report webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DeliveryReporter makes the local senders imitate a provider by posting a
+// delivery report for every message they accept. The zero value posts nothing.
+type DeliveryReporter struct {
+	URL    string
+	Secret string
+	// Status reported for each message, default delivered
+	Status string
+	// Delay before reporting, default 1s, so the report arrives after the send is logged
+	Delay time.Duration
+}
+
+// deliveryReporterFromEnv reads SMS_FAKE_REPORT_URL, SMS_FAKE_REPORT_STATUS and
+// SMS_FAKE_REPORT_DELAY, signing reports with SMS_WEBHOOK_SECRET
+func deliveryReporterFromEnv() DeliveryReporter {
+	reporter := DeliveryReporter{
+		URL:    os.Getenv("SMS_FAKE_REPORT_URL"),
+		Secret: SmsWebhookSecret(),
+		Status: os.Getenv("SMS_FAKE_REPORT_STATUS"),
+	}
+	if delay, err := time.ParseDuration(os.Getenv("SMS_FAKE_REPORT_DELAY")); err == nil {
+		reporter.Delay = delay
+	}
+	return reporter
+}
+
+func (r DeliveryReporter) report(provider string, receipts []SmsReceipt) {
+	if r.URL == "" {
+		return
+	}
+	status := r.Status
+	if status == "" {
+		status = "delivered"
+	}
+	delay := r.Delay
+	if delay == 0 {
+		delay = time.Second
+	}
+
+	go func() {
+		time.Sleep(delay)
+		for _, receipt := range receipts {
+			now := time.Now()
+			report := SmsDeliveryReport{
+				Provider:  provider,
+				MessageID: receipt.MessageID,
+				Recipient: receipt.Recipient,
+				Status:    status,
+				Timestamp: &now,
+			}
+			if err := PostDeliveryReport(r.URL, r.Secret, report); err != nil {
+				log.Println("Fake SMS provider:", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import "testing"
+
+func TestNewSmsSenderFromEnvRequiresProvider(t *testing.T) {
+	t.Setenv("SMS_PROVIDER", "")
+	if sender, err := NewSmsSenderFromEnv(); err == nil {
+		t.Fatalf("got %s sender with SMS_PROVIDER unset, want an error", sender.Name())
+	}
+
+	t.Setenv("SMS_PROVIDER", "file")
+	sender, err := NewSmsSenderFromEnv()
+	if err != nil {
+		t.Fatalf("SMS_PROVIDER=file: %v", err)
+	}
+	if sender.Name() != "file" {
+		t.Errorf("got %s sender, want file", sender.Name())
+	}
+}
@@ -0,0 +1,176 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Audiences keep access tokens and login challenge tokens from being used in place of each other
+const (
+	accessTokenAudience    = "access"
+	challengeTokenAudience = "login-2fa"
+	challengeTokenTTL      = 5 * time.Minute
+)
+
+// AccessClaims are the claims carried by an access token
+type AccessClaims struct {
+	UserID    uint   `json:"uid"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// accessTokenTTL reads ACCESS_TOKEN_TTL (e.g. "15m") and falls back to 15 minutes
+func accessTokenTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 15 * time.Minute
+}
+
+// RefreshTokenTTL reads REFRESH_TOKEN_TTL (e.g. "720h") and falls back to 30 days
+func RefreshTokenTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 30 * 24 * time.Hour
+}
+
+// placeholderJWTSecrets are values that have been published, in .env.example
+// or in the repository's history, and so cannot keep tokens from being forged
+var placeholderJWTSecrets = []string{"change-me", "campus-connect-dev-secret-change-me"}
+
+// CheckJWTSecret reports whether JWT_SECRET can sign tokens. The server does
+// not start without one.
+func CheckJWTSecret() error {
+	_, err := jwtSecret()
+	return err
+}
+
+func jwtSecret() ([]byte, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return nil, errors.New("JWT_SECRET is not set")
+	}
+	for _, placeholder := range placeholderJWTSecrets {
+		if secret == placeholder {
+			return nil, errors.New("JWT_SECRET is still a placeholder; set it to a long random value")
+		}
+	}
+	return []byte(secret), nil
+}
+
+// GenerateAccessToken signs a short-lived access token for the given user and session
+func GenerateAccessToken(userID uint, role string, sessionID uint) (string, time.Time, error) {
+	secret, err := jwtSecret()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(accessTokenTTL())
+	claims := AccessClaims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Audience:  jwt.ClaimStrings{accessTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %v", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseAccessToken validates the signature and expiry of an access token and returns its claims
+func ParseAccessToken(tokenString string) (*AccessClaims, error) {
+	secret, err := jwtSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &AccessClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired(), jwt.WithAudience(accessTokenAudience))
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %v", err)
+	}
+	return claims, nil
+}
+
+// GenerateChallengeToken signs a short-lived token proving the password step of
+// a two-factor login succeeded. It cannot be used as an access token.
+func GenerateChallengeToken(userID uint) (string, time.Time, error) {
+	secret, err := jwtSecret()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(challengeTokenTTL)
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		Audience:  jwt.ClaimStrings{challengeTokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign challenge token: %v", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseChallengeToken validates a challenge token and returns the user ID it was issued for
+func ParseChallengeToken(tokenString string) (uint, error) {
+	secret, err := jwtSecret()
+	if err != nil {
+		return 0, err
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired(), jwt.WithAudience(challengeTokenAudience))
+	if err != nil {
+		return 0, fmt.Errorf("invalid challenge token: %v", err)
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid challenge token subject: %v", err)
+	}
+	return uint(userID), nil
+}
+
+// GenerateRefreshToken returns a random opaque refresh token
+func GenerateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashToken returns the hex SHA-256 of a token so only hashes are stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
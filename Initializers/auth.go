@@ -0,0 +1,14 @@
+package initializers
+
+import (
+	"log"
+
+	"github.com/group4/campus-connect-api/Helpers"
+)
+
+// Refuse to start without a JWT secret that can keep access tokens from being forged
+func ConfigureAuth() {
+	if err := helpers.CheckJWTSecret(); err != nil {
+		log.Fatal("Failed to configure authentication: ", err)
+	}
+}
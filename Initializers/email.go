@@ -0,0 +1,16 @@
+package initializers
+
+import (
+	"log"
+
+	"github.com/group4/campus-connect-api/Helpers"
+)
+
+// Configure the email provider from environment variables
+func ConfigureEmail() {
+	sender, err := helpers.NewEmailSenderFromEnv()
+	if err != nil {
+		log.Fatal("Failed to configure email provider: ", err)
+	}
+	helpers.Email = sender
+}
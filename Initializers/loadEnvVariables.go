@@ -1,6 +1,8 @@
 package initializers
 
 import (
+	"errors"
+	"io/fs"
 	"log"
 
 	"github.com/joho/godotenv"
@@ -9,7 +11,12 @@ import (
 //loading all Environment variables to the project
 func LoadEnvVariables() {
 	err := godotenv.Load()
-	
+
+	// .env is not committed; deployments may set the variables directly
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Println("No .env file found, using the process environment")
+		return
+	}
 	if err != nil {
 		log.Fatal("Environment variable error")
 	}
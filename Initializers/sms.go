@@ -0,0 +1,16 @@
+package initializers
+
+import (
+	"log"
+
+	"github.com/group4/campus-connect-api/Helpers"
+)
+
+// Configure the SMS provider from environment variables
+func ConfigureSMS() {
+	sender, err := helpers.NewSmsSenderFromEnv()
+	if err != nil {
+		log.Fatal("Failed to configure SMS provider: ", err)
+	}
+	helpers.SMS = sender
+}
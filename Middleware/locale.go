@@ -0,0 +1,12 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+)
+
+// localize translates an API message into the language named by Accept-Language,
+// or the caller's saved locale once they are authenticated
+func localize(c *gin.Context, text string) string {
+	return helpers.Translate(helpers.RequestLocale(c.GetHeader("Accept-Language"), c.GetString("locale")), text)
+}
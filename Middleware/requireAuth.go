@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+// RequireAuth rejects requests without a valid bearer access token and stores
+// the caller on the context as "user", "userID", "role", "sessionID" and "locale"
+func RequireAuth(c *gin.Context) {
+	if status, message := authenticate(c); status != http.StatusOK {
+		c.AbortWithStatusJSON(status, gin.H{"error": localize(c, message)})
+		return
+	}
+	c.Next()
+}
+
+// OptionalAuth identifies the caller when a valid bearer token is sent but
+// lets anonymous requests through, for public routes whose output depends on the viewer
+func OptionalAuth(c *gin.Context) {
+	if c.GetHeader("Authorization") != "" {
+		authenticate(c)
+	}
+	c.Next()
+}
+
+// authenticate validates the bearer token and populates the context. It returns
+// http.StatusOK on success, otherwise the status and message to reject with.
+func authenticate(c *gin.Context) (int, string) {
+	tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !found || tokenString == "" {
+		return http.StatusUnauthorized, "Missing or malformed authorization header"
+	}
+
+	claims, err := helpers.ParseAccessToken(tokenString)
+	if err != nil {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	// Reject tokens whose session has been logged out or revoked
+	var session models.Session
+	if err := initializers.DB.First(&session, claims.SessionID).Error; err != nil || session.UserID != claims.UserID || !session.Active() {
+		return http.StatusUnauthorized, "Session has been revoked"
+	}
+
+	// Load the user so deleted accounts and role changes take effect immediately
+	var user models.User
+	if err := initializers.DB.First(&user, claims.UserID).Error; err != nil {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+	if user.Suspended() {
+		return http.StatusForbidden, "Account suspended"
+	}
+
+	c.Set("user", user)
+	c.Set("userID", user.ID)
+	c.Set("role", user.Role)
+	c.Set("sessionID", session.ID)
+	c.Set("locale", user.Locale)
+	return http.StatusOK, ""
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+// RequirePermission only lets through callers whose role grants perm. It must run after RequireAuth.
+func RequirePermission(perm models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !models.HasPermission(c.GetString("role"), perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": localize(c, "Forbidden: insufficient permissions")})
+			return
+		}
+		c.Next()
+	}
+}
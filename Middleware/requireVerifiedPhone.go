@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+// RequireVerifiedPhone blocks callers who have not confirmed their phone number. It must run after RequireAuth.
+func RequireVerifiedPhone(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	if !user.PhoneVerified() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": localize(c, "Forbidden: verify your phone number first")})
+		return
+	}
+	c.Next()
+}
@@ -1,16 +1,99 @@
 package migrations
 
 import (
+	"log"
+
 	initializers "github.com/group4/campus-connect-api/Initializers"
 	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
 )
 
 func SyncDatabase() {
+	normalizeRoles()
+
 	initializers.DB.AutoMigrate(
 		&models.User{},
 		&models.Job{},
 		&models.Timetable{},
 		&models.Event{},
 		&models.Post{},
+		&models.PostImage{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.OneTimeCode{},
+		&models.LoginAttempt{},
+		&models.AccountLockout{},
+		&models.RecoveryCode{},
+		&models.OutboxMessage{},
+		&models.Notification{},
+		&models.EventFollower{},
+		&models.NotificationPreference{},
+		&models.Broadcast{},
+		&models.BroadcastRecipient{},
+		&models.SmsMessage{},
+		&models.Comment{},
+		&models.PostReaction{},
+		&models.PostReactionCount{},
+		&models.Tag{},
+		&models.PostTag{},
+		&models.PostMention{},
+		&models.Report{},
+		&models.ModerationAction{},
+	)
+
+	stampPublishedPosts()
+	indexFeed()
+	normalizePhones()
+	movePostImages()
+}
+
+// stampPublishedPosts gives posts from before drafts and scheduling, which
+// were all published when created, their creation time as publish time
+func stampPublishedPosts() {
+	initializers.DB.Exec("UPDATE posts SET publish_at = created_at WHERE status = ? AND publish_at IS NULL", models.PostStatusPublished)
+}
+
+// movePostImages turns the single image posts had before galleries into the
+// cover of their gallery, then drops the old posts.image column
+func movePostImages() {
+	if !initializers.DB.Migrator().HasColumn(&models.Post{}, "image") {
+		return
+	}
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"INSERT INTO post_images (created_at, updated_at, post_id, path, caption, position) " +
+				"SELECT NOW(), NOW(), id, image, '', 0 FROM posts WHERE deleted_at IS NULL AND image IS NOT NULL AND image <> ''",
+		).Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&models.Post{}, "image")
+	})
+	if err != nil {
+		log.Println("Post image migration:", err)
+	}
+}
+
+// indexFeed adds the indexes the feed's keyset pagination and engagement
+// ranking read by, which struct tags cannot put on gorm.Model's CreatedAt.
+// idx_posts_feed ordered posts by creation time before the feed moved to
+// publish time.
+func indexFeed() {
+	initializers.DB.Exec("DROP INDEX IF EXISTS idx_posts_feed")
+	initializers.DB.Exec("CREATE INDEX IF NOT EXISTS idx_posts_publish_feed ON posts (publish_at DESC, id DESC)")
+	initializers.DB.Exec("CREATE INDEX IF NOT EXISTS idx_comments_created_at ON comments (created_at)")
+	initializers.DB.Exec("CREATE INDEX IF NOT EXISTS idx_post_reactions_created_at ON post_reactions (created_at)")
+}
+
+// normalizeRoles lowercases free-form roles saved before roles were enforced
+// and falls back to student for anything unrecognised
+func normalizeRoles() {
+	if !initializers.DB.Migrator().HasTable(&models.User{}) {
+		return
+	}
+	initializers.DB.Exec("UPDATE users SET role = LOWER(TRIM(role)) WHERE role IS NOT NULL")
+	initializers.DB.Exec(
+		"UPDATE users SET role = ? WHERE role IS NULL OR role NOT IN ?",
+		models.RoleStudent,
+		[]string{models.RoleStudent, models.RoleLecturer, models.RoleStaff, models.RoleModerator, models.RoleAdmin},
 	)
-}
\ No newline at end of file
+}
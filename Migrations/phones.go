@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"log"
+	"strings"
+
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+// normalizePhones rewrites stored phone numbers to E.164. Numbers that cannot
+// be parsed, and numbers that would collide with another account once
+// normalized, are left untouched and reported in the log for an admin to fix.
+func normalizePhones() {
+	if !initializers.DB.Migrator().HasTable(&models.User{}) {
+		return
+	}
+
+	// Deleted accounts still hold their number in the unique index
+	var users []models.User
+	if err := initializers.DB.Unscoped().Select("id", "phone").Order("id").Find(&users).Error; err != nil {
+		log.Println("Phone migration: failed to load users:", err)
+		return
+	}
+
+	byPhone := map[string][]models.User{}
+	for _, user := range users {
+		phone, err := helpers.NormalizePhone(user.Phone)
+		if err != nil {
+			log.Printf("Phone migration: user %d has an invalid phone number %q, left unchanged", user.ID, user.Phone)
+			continue
+		}
+		byPhone[phone] = append(byPhone[phone], user)
+	}
+
+	updated, collisions := 0, 0
+	for phone, owners := range byPhone {
+		if len(owners) > 1 {
+			collisions++
+			var entries []string
+			for _, owner := range owners {
+				entries = append(entries, owner.Phone)
+			}
+			log.Printf("Phone migration: %d users share %s (stored as %s), left unchanged", len(owners), phone, strings.Join(entries, ", "))
+			continue
+		}
+
+		owner := owners[0]
+		if owner.Phone == phone {
+			continue
+		}
+		if err := initializers.DB.Unscoped().Model(&models.User{}).Where("id = ?", owner.ID).Update("phone", phone).Error; err != nil {
+			log.Printf("Phone migration: failed to update user %d: %v", owner.ID, err)
+			continue
+		}
+		updated++
+	}
+
+	if updated > 0 || collisions > 0 {
+		log.Printf("Phone migration: normalized %d numbers, %d collisions need attention", updated, collisions)
+	}
+}
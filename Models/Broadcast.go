@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Broadcast and recipient statuses
+const (
+	BroadcastStatusSending   = "sending"
+	BroadcastStatusCompleted = "completed"
+
+	DeliveryStatusPending = "pending"
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"
+)
+
+// Broadcast is an SMS sent by staff to every user matching a filter
+type Broadcast struct {
+	gorm.Model
+	SenderID       uint   `gorm:"not null;index"`
+	Message        string `gorm:"not null"`
+	Course         string
+	Year           string
+	Role           string
+	Status         string `gorm:"not null"`
+	RecipientCount int    `gorm:"not null"`
+	SentCount      int    `gorm:"not null;default:0"`
+	FailedCount    int    `gorm:"not null;default:0"`
+	CompletedAt    *time.Time
+}
+
+// BroadcastRecipient tracks delivery of a broadcast to one user
+type BroadcastRecipient struct {
+	gorm.Model
+	BroadcastID uint   `gorm:"not null;index"`
+	UserID      uint   `gorm:"not null"`
+	Phone       string `gorm:"not null"`
+	Status      string `gorm:"not null;index"`
+	Error       string
+	SentAt      *time.Time
+}
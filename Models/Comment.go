@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is a user's comment on a post. Replies point at a top-level comment
+// through ParentID, so threads are one level deep.
+type Comment struct {
+	gorm.Model
+	PostID   uint `gorm:"not null;index"`
+	UserID   uint `gorm:"not null;index"`
+	User     User
+	ParentID *uint      `gorm:"index"`
+	Body     string     `gorm:"type:text;not null"`
+	HiddenAt *time.Time // set when a moderator hides the comment
+}
@@ -9,7 +9,9 @@ type Event struct {
 	gorm.Model
 	Quarter      string    `gorm:"not null"`
 	Month        string    `gorm:"not null"`
-	Date         time.Time `gorm:"not null"`
+	Date         time.Time `gorm:"not null;index"`
 	Title        string    `gorm:"not null"`
 	Participants string
+	// ReminderSentAt is set once followers have been reminded of the event
+	ReminderSentAt *time.Time `json:"-"`
 }
\ No newline at end of file
@@ -0,0 +1,10 @@
+package models
+
+import "gorm.io/gorm"
+
+// EventFollower is a user's RSVP to an event. Followers are notified when the event changes.
+type EventFollower struct {
+	gorm.Model
+	EventID uint `gorm:"not null;uniqueIndex:idx_event_follower"`
+	UserID  uint `gorm:"not null;uniqueIndex:idx_event_follower"`
+}
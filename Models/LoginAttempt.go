@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginAttempt records every password login so failures can be rate limited per IP
+type LoginAttempt struct {
+	gorm.Model
+	Email     string `gorm:"not null;index"`
+	IPAddress string `gorm:"not null;index"`
+	Succeeded bool   `gorm:"not null"`
+}
+
+// AccountLockout records each time an account was locked after repeated failed logins
+type AccountLockout struct {
+	gorm.Model
+	UserID         uint      `gorm:"not null;index"`
+	Email          string    `gorm:"not null;index"`
+	IPAddress      string    `gorm:"not null"`
+	FailedAttempts int       `gorm:"not null"`
+	LockedUntil    time.Time `gorm:"not null"`
+	UnlockedAt     *time.Time
+	UnlockedByID   *uint
+}
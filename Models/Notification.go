@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification categories. Preferences and channels are chosen per category.
+const (
+	NotificationCategorySocial    = "social"
+	NotificationCategoryEvents    = "events"
+	NotificationCategoryJobs      = "jobs"
+	NotificationCategoryTimetable = "timetable"
+
+	// Moderation notices are not configurable and always go in-app and by email
+	NotificationCategoryModeration = "moderation"
+)
+
+// Notification is an in-app message shown in a user's notification center
+type Notification struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	Category string `gorm:"not null"`
+	Title    string `gorm:"not null"`
+	Body     string
+	Link     string
+	ReadAt   *time.Time
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata"
+
+	"gorm.io/gorm"
+)
+
+// Delivery channels. SMS and email go through the outbox; in-app is stored directly.
+const (
+	ChannelInApp = "in_app"
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+)
+
+// DefaultTimezone is used for quiet hours when the user has not chosen one
+const DefaultTimezone = "Africa/Dar_es_Salaam"
+
+// NotificationCategories lists every category users can configure
+var NotificationCategories = []string{
+	NotificationCategoryEvents,
+	NotificationCategoryJobs,
+	NotificationCategoryTimetable,
+	NotificationCategorySocial,
+}
+
+// NotificationPreference holds a user's channel choices per category and their
+// quiet hours. Users without a row get DefaultNotificationPreference.
+type NotificationPreference struct {
+	gorm.Model
+	UserID          uint   `gorm:"not null;uniqueIndex"`
+	EventsInApp     bool   `gorm:"not null"`
+	EventsSMS       bool   `gorm:"not null"`
+	EventsEmail     bool   `gorm:"not null"`
+	JobsInApp       bool   `gorm:"not null"`
+	JobsSMS         bool   `gorm:"not null"`
+	JobsEmail       bool   `gorm:"not null"`
+	TimetableInApp  bool   `gorm:"not null"`
+	TimetableSMS    bool   `gorm:"not null"`
+	TimetableEmail  bool   `gorm:"not null"`
+	SocialInApp     bool   `gorm:"not null"`
+	SocialSMS       bool   `gorm:"not null"`
+	SocialEmail     bool   `gorm:"not null"`
+	QuietHoursStart string // "HH:MM" in Timezone, empty to disable
+	QuietHoursEnd   string
+	Timezone        string
+}
+
+// DefaultNotificationPreference sends everything in-app and texts event and timetable changes
+func DefaultNotificationPreference(userID uint) NotificationPreference {
+	return NotificationPreference{
+		UserID:         userID,
+		EventsInApp:    true,
+		EventsSMS:      true,
+		JobsInApp:      true,
+		TimetableInApp: true,
+		TimetableSMS:   true,
+		SocialInApp:    true,
+		Timezone:       DefaultTimezone,
+	}
+}
+
+// Channels returns which channels are enabled for a category
+func (p NotificationPreference) Channels(category string) (inApp, sms, email bool) {
+	switch category {
+	case NotificationCategoryEvents:
+		return p.EventsInApp, p.EventsSMS, p.EventsEmail
+	case NotificationCategoryJobs:
+		return p.JobsInApp, p.JobsSMS, p.JobsEmail
+	case NotificationCategoryTimetable:
+		return p.TimetableInApp, p.TimetableSMS, p.TimetableEmail
+	case NotificationCategorySocial:
+		return p.SocialInApp, p.SocialSMS, p.SocialEmail
+	case NotificationCategoryModeration:
+		return true, false, true
+	}
+	return true, false, false
+}
+
+// SetChannels enables or disables the channels for a category
+func (p *NotificationPreference) SetChannels(category string, inApp, sms, email bool) {
+	switch category {
+	case NotificationCategoryEvents:
+		p.EventsInApp, p.EventsSMS, p.EventsEmail = inApp, sms, email
+	case NotificationCategoryJobs:
+		p.JobsInApp, p.JobsSMS, p.JobsEmail = inApp, sms, email
+	case NotificationCategoryTimetable:
+		p.TimetableInApp, p.TimetableSMS, p.TimetableEmail = inApp, sms, email
+	case NotificationCategorySocial:
+		p.SocialInApp, p.SocialSMS, p.SocialEmail = inApp, sms, email
+	}
+}
+
+// ParseClock parses an "HH:MM" time of day into minutes after midnight
+func ParseClock(clock string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(clock, "%d:%d", &hour, &minute); err != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", clock)
+	}
+	return hour*60 + minute, nil
+}
+
+// QuietUntil returns when the quiet hours covering t end, or the zero time if t
+// is outside quiet hours. Windows may wrap past midnight, e.g. 22:00-06:00.
+func (p NotificationPreference) QuietUntil(t time.Time) time.Time {
+	if p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return time.Time{}
+	}
+	start, err := ParseClock(p.QuietHoursStart)
+	if err != nil {
+		return time.Time{}
+	}
+	end, err := ParseClock(p.QuietHoursEnd)
+	if err != nil || start == end {
+		return time.Time{}
+	}
+
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		location, _ = time.LoadLocation(DefaultTimezone)
+	}
+	local := t.In(location)
+	now := local.Hour()*60 + local.Minute()
+
+	var quiet bool
+	if start < end {
+		quiet = now >= start && now < end
+	} else {
+		quiet = now >= start || now < end
+	}
+	if !quiet {
+		return time.Time{}
+	}
+
+	endToday := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, location)
+	if !endToday.After(local) {
+		endToday = endToday.AddDate(0, 0, 1)
+	}
+	return endToday
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Purposes a one-time code can be issued for. A password reset sends one code
+// by SMS and a different one by email, so only the SMS code proves the user
+// holds the phone.
+const (
+	OTPPurposePasswordReset      = "password_reset"
+	OTPPurposePasswordResetEmail = "password_reset_email"
+	OTPPurposePhoneVerification  = "phone_verification"
+)
+
+// OneTimeCode is a short-lived numeric code sent by SMS or email. Only its bcrypt hash is stored.
+type OneTimeCode struct {
+	gorm.Model
+	UserID     uint      `gorm:"not null;index"`
+	Purpose    string    `gorm:"not null;index"`
+	CodeHash   string    `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	Attempts   int       `gorm:"not null;default:0"`
+	ConsumedAt *time.Time
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Outbox message statuses. Failed messages are retried until they run out of
+// attempts and become dead.
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
+	OutboxStatusDead    = "dead"
+)
+
+// RedactedBody replaces the text of messages carrying one-time codes, in the
+// outbox shown to admins and in the SMS log
+const RedactedBody = "[redacted]"
+
+// OutboxMessage is a persisted outbound message delivered by the outbox worker.
+// Subject and HTMLBody are only used by email; Body is its plain text part.
+// Sensitive messages carry one-time codes: their bodies are cleared once they
+// are sent or dead.
+type OutboxMessage struct {
+	gorm.Model
+	Channel       string `gorm:"not null;index"`
+	Recipient     string `gorm:"not null"`
+	Subject       string
+	Body          string `gorm:"not null"`
+	HTMLBody      string
+	Sensitive     bool      `gorm:"not null;default:false"`
+	Status        string    `gorm:"not null;default:pending;index"`
+	Attempts      int       `gorm:"not null;default:0"`
+	MaxAttempts   int       `gorm:"not null"`
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	LastError     string
+	SentAt        *time.Time
+}
+
+// Redacted returns the message with the body of a sensitive message hidden
+func (m OutboxMessage) Redacted() OutboxMessage {
+	if m.Sensitive {
+		m.Body = RedactedBody
+		m.HTMLBody = ""
+	}
+	return m
+}
@@ -1,14 +1,40 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Post statuses. Drafts and scheduled posts are only visible to their author
+// until they are published.
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+)
+
+// PostStatuses lists every post status
+var PostStatuses = []string{PostStatusDraft, PostStatusScheduled, PostStatusPublished}
+
+// ValidPostStatus reports whether status is one of PostStatuses
+func ValidPostStatus(status string) bool {
+	for _, s := range PostStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Post struct {
 	gorm.Model
-	Image       string
 	Title       string `gorm:"not null"`
 	Description string
 	UserID      uint
 	User        User
+	Images      []PostImage
+	Status      string     `gorm:"not null;default:published;index:idx_posts_status_publish_at"`
+	PublishAt   *time.Time `gorm:"index:idx_posts_status_publish_at"` // when a scheduled post goes live, or when a published one did
+	HiddenAt    *time.Time `gorm:"index"`                             // set when a moderator hides the post
 }
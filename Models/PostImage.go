@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// MaxPostImages is how many images one post's gallery can hold
+const MaxPostImages = 10
+
+// PostImage is one image in a post's gallery. Images are shown by Position,
+// which runs from 0 (the cover) without gaps.
+type PostImage struct {
+	gorm.Model
+	PostID   uint   `gorm:"not null;index"`
+	Path     string `gorm:"not null"`
+	Caption  string
+	Position int `gorm:"not null"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "gorm.io/gorm"
+
+// Reaction types a user can leave on a post
+const (
+	ReactionLike  = "like"
+	ReactionLove  = "love"
+	ReactionLaugh = "laugh"
+	ReactionWow   = "wow"
+	ReactionSad   = "sad"
+	ReactionAngry = "angry"
+)
+
+// ReactionTypes lists every reaction type, in display order
+var ReactionTypes = []string{ReactionLike, ReactionLove, ReactionLaugh, ReactionWow, ReactionSad, ReactionAngry}
+
+// ValidReaction reports whether reaction is one of ReactionTypes
+func ValidReaction(reaction string) bool {
+	for _, t := range ReactionTypes {
+		if t == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// PostReaction is a user's reaction to a post. Each user has at most one per post.
+type PostReaction struct {
+	gorm.Model
+	PostID uint   `gorm:"not null;uniqueIndex:idx_post_reaction"`
+	UserID uint   `gorm:"not null;uniqueIndex:idx_post_reaction;index"`
+	Type   string `gorm:"not null"`
+}
+
+// PostReactionCount is the running total of one reaction type on a post. It is
+// updated with every PostReaction change so listings never count reactions row by row.
+type PostReactionCount struct {
+	PostID uint   `gorm:"primaryKey"`
+	Type   string `gorm:"primaryKey"`
+	Count  int64  `gorm:"not null"`
+}
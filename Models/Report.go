@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reasons a user can give when reporting content
+const (
+	ReportReasonSpam           = "spam"
+	ReportReasonHarassment     = "harassment"
+	ReportReasonHateSpeech     = "hate_speech"
+	ReportReasonViolence       = "violence"
+	ReportReasonSexualContent  = "sexual_content"
+	ReportReasonMisinformation = "misinformation"
+	ReportReasonOther          = "other"
+)
+
+// ReportReasons lists every report reason
+var ReportReasons = []string{
+	ReportReasonSpam,
+	ReportReasonHarassment,
+	ReportReasonHateSpeech,
+	ReportReasonViolence,
+	ReportReasonSexualContent,
+	ReportReasonMisinformation,
+	ReportReasonOther,
+}
+
+// ValidReportReason reports whether reason is one of ReportReasons
+func ValidReportReason(reason string) bool {
+	for _, r := range ReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Kinds of content that can be reported and moderated. Moderation actions on
+// accounts use ModerationTargetUser.
+const (
+	ReportTargetPost     = "post"
+	ReportTargetComment  = "comment"
+	ModerationTargetUser = "user"
+)
+
+// Report statuses. Reports stay open until a moderator acts on their content.
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// Moderation actions. Hide, restore and delete act on the reported content;
+// warn and suspend act on its author; dismiss closes reports without acting.
+const (
+	ModerationHide      = "hide"
+	ModerationRestore   = "restore"
+	ModerationDelete    = "delete"
+	ModerationWarn      = "warn"
+	ModerationSuspend   = "suspend"
+	ModerationDismiss   = "dismiss"
+	ModerationUnsuspend = "unsuspend"
+)
+
+// ModerationActions lists the actions a moderator can take on a report
+var ModerationActions = []string{
+	ModerationHide,
+	ModerationRestore,
+	ModerationDelete,
+	ModerationWarn,
+	ModerationSuspend,
+	ModerationDismiss,
+}
+
+// Report is a user's flag on a post or comment. Each user can report the same content once.
+type Report struct {
+	gorm.Model
+	ReporterID   uint `gorm:"not null;uniqueIndex:idx_report_target"`
+	Reporter     User
+	TargetType   string `gorm:"not null;uniqueIndex:idx_report_target;index:idx_report_content"`
+	TargetID     uint   `gorm:"not null;uniqueIndex:idx_report_target;index:idx_report_content"`
+	Reason       string `gorm:"not null"`
+	Details      string
+	Status       string `gorm:"not null;default:open;index"`
+	Action       string
+	ResolvedByID *uint
+	ResolvedAt   *time.Time
+}
+
+// ModerationAction records every action a moderator takes, for auditing
+type ModerationAction struct {
+	gorm.Model
+	ModeratorID    uint   `gorm:"not null;index"`
+	TargetType     string `gorm:"not null"`
+	TargetID       uint   `gorm:"not null"`
+	UserID         uint   `gorm:"not null;index"`
+	Action         string `gorm:"not null"`
+	Note           string
+	SuspendedUntil *time.Time
+}
@@ -0,0 +1,69 @@
+package models
+
+// Roles a user can hold. Stored lowercase in users.role.
+const (
+	RoleStudent   = "student"
+	RoleLecturer  = "lecturer"
+	RoleStaff     = "staff"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permission names an action guarded by role
+type Permission string
+
+const (
+	PermManageJobs       Permission = "jobs:manage"
+	PermManageEvents     Permission = "events:manage"
+	PermManageTimetables Permission = "timetables:manage"
+	PermManageRoles      Permission = "users:manage-roles"
+	PermManageUsers      Permission = "users:manage"
+	PermModeratePosts    Permission = "posts:moderate"
+	PermTwoFactor        Permission = "account:two-factor"
+	PermManageOutbox     Permission = "outbox:manage"
+	PermBroadcast        Permission = "sms:broadcast"
+)
+
+// RolePermissions is the permission matrix: each role maps to the permissions it grants
+var RolePermissions = map[string][]Permission{
+	RoleStudent:  {},
+	RoleLecturer: {},
+	RoleStaff: {
+		PermManageJobs,
+		PermManageEvents,
+		PermManageTimetables,
+		PermTwoFactor,
+		PermBroadcast,
+	},
+	RoleModerator: {
+		PermModeratePosts,
+		PermTwoFactor,
+	},
+	RoleAdmin: {
+		PermManageJobs,
+		PermManageEvents,
+		PermManageTimetables,
+		PermManageRoles,
+		PermManageUsers,
+		PermModeratePosts,
+		PermTwoFactor,
+		PermManageOutbox,
+		PermBroadcast,
+	},
+}
+
+// ValidRole reports whether role is one of the defined roles
+func ValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// HasPermission reports whether role grants perm
+func HasPermission(role string, perm Permission) bool {
+	for _, p := range RolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session is a logged-in device. Its refresh tokens are rotated on every use.
+type Session struct {
+	gorm.Model
+	UserID        uint `gorm:"not null;index"`
+	UserAgent     string
+	IPAddress     string
+	LastUsedAt    time.Time
+	ExpiresAt     time.Time `gorm:"not null"`
+	RevokedAt     *time.Time
+	RefreshTokens []RefreshToken `gorm:"foreignKey:SessionID"`
+}
+
+// RefreshToken stores the SHA-256 hash of a refresh token issued for a session
+type RefreshToken struct {
+	gorm.Model
+	SessionID uint `gorm:"not null;index"`
+	Session   Session
+	TokenHash string `gorm:"uniqueIndex;not null"`
+	UsedAt    *time.Time
+}
+
+// Active reports whether the session can still be used
+func (s Session) Active() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
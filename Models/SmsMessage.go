@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SMS log statuses. A message is queued before it is handed to the provider,
+// sent once the provider accepts it, then delivered or failed according to the
+// provider's delivery report.
+const (
+	SmsStatusQueued    = "queued"
+	SmsStatusSent      = "sent"
+	SmsStatusDelivered = "delivered"
+	SmsStatusFailed    = "failed"
+)
+
+// SmsMessage logs one SMS to one recipient sent through the configured provider
+type SmsMessage struct {
+	gorm.Model
+	Provider          string `gorm:"not null;index:idx_sms_provider_message"`
+	ProviderMessageID string `gorm:"index:idx_sms_provider_message"`
+	Recipient         string `gorm:"not null;index"`
+	Body              string `gorm:"not null"`
+	Status            string `gorm:"not null;default:queued;index"`
+	Error             string
+	SentAt            *time.Time
+	DeliveredAt       *time.Time
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Tag is a #hashtag used in at least one post, stored lowercase without the #
+type Tag struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time
+}
+
+// PostTag links a post to a tag parsed from its title or description.
+// CreatedAt is when the tag was first used on the post, for trending tags.
+type PostTag struct {
+	PostID    uint      `gorm:"primaryKey"`
+	TagID     uint      `gorm:"primaryKey;index"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// PostMention links a post to a user @mentioned in its title or description
+type PostMention struct {
+	PostID    uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
@@ -14,4 +14,5 @@ type Timetable struct {
 	Room        string    `gorm:"not null"`
 	Time        time.Time `gorm:"not null"`
 	Instructor  string    `gorm:"not null"`
+	Course      string    `gorm:"index"`
 }
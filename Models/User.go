@@ -1,18 +1,49 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type User struct {
 	gorm.Model
-	Name         string `gorm:"not null"`
-	ProfileImage string
-	Role         string
-	Course       string
-	Phone        string `gorm:"unique;not null"`
-	Year         string
-	Password     string `gorm:"not null"`
-	Email        string `gorm:"unique;not null"`
-	Posts        []Post `gorm:"foreignKey:UserID"`
+	Name              string `gorm:"not null"`
+	ProfileImage      string
+	Role              string `gorm:"not null;default:student"`
+	Course            string
+	Phone             string `gorm:"unique;not null"`
+	PhoneVerifiedAt   *time.Time
+	FailedLogins      int        `gorm:"not null;default:0" json:"-"`
+	LastFailedLoginAt *time.Time `json:"-"`
+	LockedUntil       *time.Time `json:"-"`
+	SuspendedUntil    *time.Time `json:"-"`
+	TOTPSecret        string     `json:"-"`
+	TOTPEnabledAt     *time.Time `json:"-"`
+	TOTPLastStep      int64      `gorm:"not null;default:0" json:"-"`
+	Year              string
+	Password          string `gorm:"not null" json:"-"`
+	Email             string `gorm:"unique;not null"`
+	Locale            string `gorm:"not null;default:en"`
+	Posts             []Post `gorm:"foreignKey:UserID"`
+}
+
+// PhoneVerified reports whether the user has confirmed their phone number
+func (u User) PhoneVerified() bool {
+	return u.PhoneVerifiedAt != nil
+}
+
+// Locked reports whether the account is temporarily locked after failed logins
+func (u User) Locked() bool {
+	return u.LockedUntil != nil && time.Now().Before(*u.LockedUntil)
+}
+
+// Suspended reports whether a moderator has suspended the account
+func (u User) Suspended() bool {
+	return u.SuspendedUntil != nil && time.Now().Before(*u.SuspendedUntil)
+}
+
+// TwoFactorEnabled reports whether login requires a TOTP code
+func (u User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
 }
@@ -0,0 +1,147 @@
+package notifications
+
+import (
+	"log"
+	"time"
+
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
+)
+
+// broadcastStallTimeout is how long a sending broadcast may go without
+// recording a batch before it is taken to have lost its sender
+const broadcastStallTimeout = 5 * time.Minute
+
+// StartBroadcastWorker resumes stalled broadcasts, usually ones left sending
+// when the server restarted, at startup and then every minute in the background
+func StartBroadcastWorker() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			if err := resumeStalledBroadcasts(); err != nil {
+				log.Println("Broadcast worker:", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// resumeStalledBroadcasts restarts delivery of each sending broadcast that has
+// not progressed for broadcastStallTimeout. Each one is claimed by touching it
+// first, so only one server resumes it. The batch that was in flight when the
+// sender stopped was never recorded, so its recipients may get the message twice.
+func resumeStalledBroadcasts() error {
+	stalledSince := time.Now().Add(-broadcastStallTimeout)
+	var ids []uint
+	if err := initializers.DB.Model(&models.Broadcast{}).
+		Where("status = ? AND updated_at < ?", models.BroadcastStatusSending, stalledSince).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		claim := initializers.DB.Model(&models.Broadcast{}).
+			Where("id = ? AND status = ? AND updated_at < ?", id, models.BroadcastStatusSending, stalledSince).
+			Update("updated_at", time.Now())
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+		log.Println("Broadcast worker: resuming broadcast", id)
+		SendBroadcastAsync(id)
+	}
+	return nil
+}
+
+// SendBroadcastAsync delivers a broadcast's pending recipients in the background
+func SendBroadcastAsync(broadcastID uint) {
+	go func() {
+		if err := sendBroadcast(broadcastID); err != nil {
+			log.Println("Broadcast", broadcastID, "failed:", err)
+		}
+	}()
+}
+
+// sendBroadcast texts pending recipients in batches sized for the SMS provider
+// and records the outcome on each recipient. A batch the provider refuses fails
+// as a whole; a number it rejects fails on its own.
+func sendBroadcast(broadcastID uint) error {
+	var broadcast models.Broadcast
+	if err := initializers.DB.First(&broadcast, broadcastID).Error; err != nil {
+		return err
+	}
+
+	batchSize := helpers.SmsBatchSize()
+	for {
+		var batch []models.BroadcastRecipient
+		if err := initializers.DB.Where("broadcast_id = ? AND status = ?", broadcast.ID, models.DeliveryStatusPending).
+			Order("id").
+			Limit(batchSize).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		phones := make([]string, len(batch))
+		for i, recipient := range batch {
+			phones[i] = recipient.Phone
+		}
+
+		// Receipts come back in recipient order; failed recipients are grouped by error
+		var sentIDs []uint
+		failedIDs := map[string][]uint{}
+		failedCount := 0
+		receipts, err := helpers.SendBatchSMS(broadcast.Message, phones)
+		for i, recipient := range batch {
+			failure := ""
+			if err != nil {
+				failure = err.Error()
+			} else if i < len(receipts) {
+				failure = receipts[i].Error
+			}
+			if failure == "" {
+				sentIDs = append(sentIDs, recipient.ID)
+				continue
+			}
+			failedIDs[failure] = append(failedIDs[failure], recipient.ID)
+			failedCount++
+		}
+
+		if err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+			if len(sentIDs) > 0 {
+				if err := tx.Model(&models.BroadcastRecipient{}).Where("id IN ?", sentIDs).Updates(map[string]interface{}{
+					"status":  models.DeliveryStatusSent,
+					"sent_at": time.Now(),
+				}).Error; err != nil {
+					return err
+				}
+			}
+			for message, ids := range failedIDs {
+				if err := tx.Model(&models.BroadcastRecipient{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+					"status": models.DeliveryStatusFailed,
+					"error":  message,
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Model(&broadcast).Updates(map[string]interface{}{
+				"sent_count":   gorm.Expr("sent_count + ?", len(sentIDs)),
+				"failed_count": gorm.Expr("failed_count + ?", failedCount),
+			}).Error
+		}); err != nil {
+			return err
+		}
+	}
+
+	return initializers.DB.Model(&broadcast).Updates(map[string]interface{}{
+		"status":       models.BroadcastStatusCompleted,
+		"completed_at": time.Now(),
+	}).Error
+}
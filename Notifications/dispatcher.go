@@ -0,0 +1,176 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+// Message is a notification to deliver to one or more users
+type Message struct {
+	Category string
+	// Template is a message catalog key. The title and body are rendered from
+	// its ".title" and ".body" variants in each recipient's locale, with Data.
+	Template string
+	Data     map[string]interface{}
+	Link     string
+	// EmailTemplate picks a dedicated email template, filled with Data. By
+	// default the email repeats the title, body and link.
+	EmailTemplate string
+}
+
+// localized is a message rendered in one locale
+type localized struct {
+	title, body, sms string
+}
+
+func (msg Message) render(locale string) (localized, error) {
+	var out localized
+	var err error
+	if out.title, err = helpers.RenderMessage(locale, msg.Template+".title", msg.Data); err != nil {
+		return out, err
+	}
+	if out.body, err = helpers.RenderMessage(locale, msg.Template+".body", msg.Data); err != nil {
+		return out, err
+	}
+	out.sms, err = helpers.RenderMessage(locale, helpers.MessageNotificationSMS, map[string]interface{}{
+		"Heading": out.title,
+		"Message": out.body,
+	})
+	return out, err
+}
+
+// email renders the message for one user
+func (msg Message) email(user models.User, text localized) (helpers.EmailMessage, error) {
+	template := msg.EmailTemplate
+	if template == "" {
+		template = helpers.EmailTemplateNotification
+	}
+	data := map[string]interface{}{}
+	for key, value := range msg.Data {
+		data[key] = value
+	}
+	data["Name"] = user.Name
+	data["Heading"] = text.title
+	data["Message"] = text.body
+	data["Link"] = msg.Link
+	return helpers.RenderEmail(user.Email, user.Locale, template, data)
+}
+
+// Dispatch delivers a notification to each user on the channels their
+// preferences enable for its category, in the user's locale. SMS is only sent
+// to verified numbers. SMS and email are held until quiet hours end.
+// Controllers raise every notification through here.
+func Dispatch(userIDs []uint, msg Message) error {
+	userIDs = uniqueIDs(userIDs)
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	var users []models.User
+	if err := initializers.DB.Select("id", "name", "email", "locale", "phone", "phone_verified_at").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return fmt.Errorf("failed to load recipients: %v", err)
+	}
+	preferences, err := PreferencesFor(userIDs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	texts := map[string]localized{}
+	var rows []models.Notification
+	for _, user := range users {
+		preference := preferences[user.ID]
+		inApp, sms, email := preference.Channels(msg.Category)
+
+		text, ok := texts[user.Locale]
+		if !ok {
+			if text, err = msg.render(user.Locale); err != nil {
+				return err
+			}
+			texts[user.Locale] = text
+		}
+
+		if inApp {
+			rows = append(rows, models.Notification{
+				UserID:   user.ID,
+				Category: msg.Category,
+				Title:    text.title,
+				Body:     text.body,
+				Link:     msg.Link,
+			})
+		}
+
+		sendAt := now
+		if quietUntil := preference.QuietUntil(now); !quietUntil.IsZero() {
+			sendAt = quietUntil
+		}
+
+		if sms && user.PhoneVerified() {
+			if err := queueAt(models.OutboxMessage{Channel: models.ChannelSMS, Body: text.sms}, []string{user.Phone}, sendAt); err != nil {
+				return err
+			}
+		}
+
+		if email && user.Email != "" {
+			rendered, err := msg.email(user, text)
+			if err != nil {
+				return err
+			}
+			if err := queueEmailAt(rendered, sendAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(rows) > 0 {
+		if err := initializers.DB.Create(&rows).Error; err != nil {
+			return fmt.Errorf("failed to store notifications: %v", err)
+		}
+	}
+	return nil
+}
+
+// DispatchAsync runs Dispatch in the background and logs failures, so request
+// handlers are not slowed down by large audiences
+func DispatchAsync(userIDs []uint, msg Message) {
+	go func() {
+		if err := Dispatch(userIDs, msg); err != nil {
+			log.Println("Failed to dispatch notification:", err)
+		}
+	}()
+}
+
+// PreferencesFor returns each user's notification preferences, falling back to
+// the defaults for users who never saved any
+func PreferencesFor(userIDs []uint) (map[uint]models.NotificationPreference, error) {
+	var saved []models.NotificationPreference
+	if err := initializers.DB.Where("user_id IN ?", userIDs).Find(&saved).Error; err != nil {
+		return nil, fmt.Errorf("failed to load notification preferences: %v", err)
+	}
+
+	preferences := make(map[uint]models.NotificationPreference, len(userIDs))
+	for _, userID := range userIDs {
+		preferences[userID] = models.DefaultNotificationPreference(userID)
+	}
+	for _, preference := range saved {
+		preferences[preference.UserID] = preference
+	}
+	return preferences, nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := ids[:0:0]
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,225 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	outboxMaxAttempts = 6
+	outboxBatchSize   = 50
+	outboxBackoffBase = 30 * time.Second
+	outboxBackoffMax  = time.Hour
+	// outboxLease keeps a claimed message from being picked up again while it is being delivered
+	outboxLease = 2 * time.Minute
+)
+
+// QueueSMS stores one outbox message per recipient for the worker to deliver
+func QueueSMS(message string, recipients []string) error {
+	return queueAt(models.OutboxMessage{Channel: models.ChannelSMS, Body: message}, recipients, time.Now())
+}
+
+// QueueEmail stores an email in the outbox for the worker to deliver. Emails
+// without an address are dropped.
+func QueueEmail(email helpers.EmailMessage) error {
+	return queueEmailAt(email, time.Now())
+}
+
+// QueueTemplatedSMS renders a message template in the locale and queues it for each recipient
+func QueueTemplatedSMS(locale, key string, data map[string]interface{}, recipients []string) error {
+	message, err := helpers.RenderMessage(locale, key, data)
+	if err != nil {
+		return err
+	}
+	return QueueSMS(message, recipients)
+}
+
+// QueueCodeSMS is QueueTemplatedSMS for messages carrying a one-time code. They
+// are marked sensitive, so the code is not kept once the message is delivered.
+func QueueCodeSMS(locale, key string, data map[string]interface{}, recipients []string) error {
+	message, err := helpers.RenderMessage(locale, key, data)
+	if err != nil {
+		return err
+	}
+	return queueAt(models.OutboxMessage{Channel: models.ChannelSMS, Body: message, Sensitive: true}, recipients, time.Now())
+}
+
+// QueueTemplatedEmail renders an email template in the locale and queues the result
+func QueueTemplatedEmail(to, locale, template string, data map[string]interface{}) error {
+	email, err := helpers.RenderEmail(to, locale, template, data)
+	if err != nil {
+		return err
+	}
+	return QueueEmail(email)
+}
+
+// QueueCodeEmail is QueueTemplatedEmail for emails carrying a one-time code,
+// marked sensitive like QueueCodeSMS
+func QueueCodeEmail(to, locale, template string, data map[string]interface{}) error {
+	email, err := helpers.RenderEmail(to, locale, template, data)
+	if err != nil || email.To == "" {
+		return err
+	}
+	message := emailOutboxMessage(email)
+	message.Sensitive = true
+	return queueAt(message, []string{email.To}, time.Now())
+}
+
+func queueEmailAt(email helpers.EmailMessage, sendAt time.Time) error {
+	if email.To == "" {
+		return nil
+	}
+	return queueAt(emailOutboxMessage(email), []string{email.To}, sendAt)
+}
+
+func emailOutboxMessage(email helpers.EmailMessage) models.OutboxMessage {
+	return models.OutboxMessage{
+		Channel:  models.ChannelEmail,
+		Subject:  email.Subject,
+		Body:     email.Text,
+		HTMLBody: email.HTML,
+	}
+}
+
+// queueAt stores a copy of the message for each recipient that the worker
+// will not deliver before sendAt
+func queueAt(message models.OutboxMessage, recipients []string, sendAt time.Time) error {
+	var messages []models.OutboxMessage
+	for _, recipient := range recipients {
+		message.Recipient = recipient
+		message.Status = models.OutboxStatusPending
+		message.MaxAttempts = outboxMaxAttempts
+		message.NextAttemptAt = sendAt
+		messages = append(messages, message)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	if err := initializers.DB.Create(&messages).Error; err != nil {
+		return fmt.Errorf("failed to queue %s: %v", message.Channel, err)
+	}
+	return nil
+}
+
+// RetryOutboxMessage puts a failed or dead message back in the queue with a fresh set of attempts
+func RetryOutboxMessage(message *models.OutboxMessage) error {
+	return initializers.DB.Model(message).Updates(map[string]interface{}{
+		"status":          models.OutboxStatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"last_error":      "",
+	}).Error
+}
+
+// StartOutboxWorker polls the outbox every OUTBOX_POLL_INTERVAL (default 5s) in the background
+func StartOutboxWorker() {
+	interval := 5 * time.Second
+	if d, err := time.ParseDuration(os.Getenv("OUTBOX_POLL_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := processOutbox(); err != nil {
+				log.Println("Outbox worker:", err)
+			}
+		}
+	}()
+}
+
+// processOutbox claims a batch of due messages and attempts delivery of each
+func processOutbox() error {
+	var due []models.OutboxMessage
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []string{models.OutboxStatusPending, models.OutboxStatusFailed}, time.Now()).
+			Order("next_attempt_at").
+			Limit(outboxBatchSize).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(due))
+		for i, message := range due {
+			ids[i] = message.ID
+		}
+		return tx.Model(&models.OutboxMessage{}).Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(outboxLease)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		deliver(&due[i])
+	}
+	return nil
+}
+
+// deliver sends one message and records the outcome, scheduling a retry with
+// exponential backoff or dead-lettering it once attempts run out. The body of
+// a sensitive message is cleared once it is sent or dead.
+func deliver(message *models.OutboxMessage) {
+	var err error
+	switch message.Channel {
+	case models.ChannelSMS:
+		if message.Sensitive {
+			err = helpers.SendSensitiveSMS(message.Body, []string{message.Recipient})
+		} else {
+			err = helpers.SendSMS(message.Body, []string{message.Recipient})
+		}
+	case models.ChannelEmail:
+		err = helpers.SendEmail(helpers.EmailMessage{
+			To:      message.Recipient,
+			Subject: message.Subject,
+			Text:    message.Body,
+			HTML:    message.HTMLBody,
+		})
+	default:
+		err = fmt.Errorf("unknown channel %q", message.Channel)
+	}
+
+	now := time.Now()
+	attempts := message.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+
+	switch {
+	case err == nil:
+		updates["status"] = models.OutboxStatusSent
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+	case attempts >= message.MaxAttempts:
+		updates["status"] = models.OutboxStatusDead
+		updates["last_error"] = err.Error()
+	default:
+		backoff := outboxBackoffBase * time.Duration(math.Pow(2, float64(attempts-1)))
+		if backoff > outboxBackoffMax {
+			backoff = outboxBackoffMax
+		}
+		updates["status"] = models.OutboxStatusFailed
+		updates["next_attempt_at"] = now.Add(backoff)
+		updates["last_error"] = err.Error()
+	}
+	if message.Sensitive && updates["status"] != models.OutboxStatusFailed {
+		updates["body"] = ""
+		updates["html_body"] = ""
+	}
+
+	if dbErr := initializers.DB.Model(message).Updates(updates).Error; dbErr != nil {
+		log.Println("Outbox worker: failed to record delivery of message", message.ID, ":", dbErr)
+	}
+}
@@ -0,0 +1,75 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+// StartEventReminders reminds followers of events starting within
+// EVENT_REMINDER_LEAD (default 24h), checking every minute in the background
+func StartEventReminders() {
+	lead := 24 * time.Hour
+	if d, err := time.ParseDuration(os.Getenv("EVENT_REMINDER_LEAD")); err == nil && d > 0 {
+		lead = d
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := sendEventReminders(lead); err != nil {
+				log.Println("Event reminders:", err)
+			}
+		}
+	}()
+}
+
+// sendEventReminders dispatches one reminder per upcoming event. Each event is
+// claimed by setting reminder_sent_at first, so a reminder is never sent twice.
+func sendEventReminders(lead time.Duration) error {
+	now := time.Now()
+	var events []models.Event
+	if err := initializers.DB.Where("reminder_sent_at IS NULL AND date > ? AND date <= ?", now, now.Add(lead)).
+		Order("date").
+		Find(&events).Error; err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		claim := initializers.DB.Model(&models.Event{}).
+			Where("id = ? AND reminder_sent_at IS NULL", event.ID).
+			Update("reminder_sent_at", now)
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		var followerIDs []uint
+		if err := initializers.DB.Model(&models.EventFollower{}).Where("event_id = ?", event.ID).Pluck("user_id", &followerIDs).Error; err != nil {
+			return err
+		}
+
+		if err := Dispatch(followerIDs, Message{
+			Category: models.NotificationCategoryEvents,
+			Template: helpers.MessageEventReminder,
+			Data: map[string]interface{}{
+				"Title":        event.Title,
+				"Date":         event.Date,
+				"Participants": event.Participants,
+			},
+			Link:          fmt.Sprintf("/api/events/%d", event.ID),
+			EmailTemplate: helpers.EmailTemplateEventReminder,
+		}); err != nil {
+			log.Println("Event reminders: failed to remind followers of event", event.ID, ":", err)
+		}
+	}
+	return nil
+}
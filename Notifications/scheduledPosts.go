@@ -0,0 +1,69 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+)
+
+// MentionMessage tells users the author mentioned them in the post
+func MentionMessage(post models.Post, author models.User) Message {
+	return Message{
+		Category: models.NotificationCategorySocial,
+		Template: helpers.MessageMentioned,
+		Data:     map[string]interface{}{"Name": author.Name, "Title": post.Title},
+		Link:     fmt.Sprintf("/api/posts/%d", post.ID),
+	}
+}
+
+// StartPostScheduler publishes scheduled posts once their publish time has
+// passed, checking every minute in the background
+func StartPostScheduler() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := publishScheduledPosts(); err != nil {
+				log.Println("Post scheduler:", err)
+			}
+		}
+	}()
+}
+
+// publishScheduledPosts publishes each due post and tells the users mentioned
+// in it, who were not notified while it was scheduled. Each post is claimed by
+// changing its status first, so mentions are never notified twice.
+func publishScheduledPosts() error {
+	var posts []models.Post
+	if err := initializers.DB.Preload("User").
+		Where("status = ? AND publish_at <= ?", models.PostStatusScheduled, time.Now()).
+		Order("publish_at").
+		Find(&posts).Error; err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		claim := initializers.DB.Model(&models.Post{}).
+			Where("id = ? AND status = ?", post.ID, models.PostStatusScheduled).
+			Update("status", models.PostStatusPublished)
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		var mentionedIDs []uint
+		if err := initializers.DB.Model(&models.PostMention{}).Where("post_id = ? AND user_id <> ?", post.ID, post.UserID).Pluck("user_id", &mentionedIDs).Error; err != nil {
+			return err
+		}
+		if err := Dispatch(mentionedIDs, MentionMessage(post, post.User)); err != nil {
+			log.Println("Post scheduler: failed to notify users mentioned in post", post.ID, ":", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package notifications
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/group4/campus-connect-api/Helpers"
+	initializers "github.com/group4/campus-connect-api/Initializers"
+	models "github.com/group4/campus-connect-api/Models"
+	"gorm.io/gorm"
+)
+
+// loggingSmsSender wraps the configured provider and records every message in
+// the SMS log: queued before the provider is called, then sent or failed.
+// Messages sent with SendSensitive are logged without their text.
+type loggingSmsSender struct {
+	next helpers.SmsSender
+}
+
+// EnableSmsLog makes every message sent through helpers.SendSMS or SendBatchSMS appear in the SMS log
+func EnableSmsLog() {
+	if helpers.SMS == nil {
+		return
+	}
+	if _, ok := helpers.SMS.(*loggingSmsSender); ok {
+		return
+	}
+	helpers.SMS = &loggingSmsSender{next: helpers.SMS}
+}
+
+func (l *loggingSmsSender) Name() string {
+	return l.next.Name()
+}
+
+func (l *loggingSmsSender) MaxBatchSize() int {
+	if sizer, ok := l.next.(helpers.SmsBatchSizer); ok {
+		return sizer.MaxBatchSize()
+	}
+	return 0
+}
+
+func (l *loggingSmsSender) Send(message string, recipients []string) ([]helpers.SmsReceipt, error) {
+	return l.send(message, message, recipients)
+}
+
+func (l *loggingSmsSender) SendSensitive(message string, recipients []string) ([]helpers.SmsReceipt, error) {
+	return l.send(message, models.RedactedBody, recipients)
+}
+
+// send hands the message to the provider and logs it with loggedBody as its text
+func (l *loggingSmsSender) send(message, loggedBody string, recipients []string) ([]helpers.SmsReceipt, error) {
+	entries := make([]models.SmsMessage, len(recipients))
+	for i, recipient := range recipients {
+		entries[i] = models.SmsMessage{
+			Provider:  l.next.Name(),
+			Recipient: recipient,
+			Body:      loggedBody,
+			Status:    models.SmsStatusQueued,
+		}
+	}
+	if len(entries) > 0 {
+		if err := initializers.DB.Create(&entries).Error; err != nil {
+			log.Println("SMS log: failed to record messages:", err)
+			return l.next.Send(message, recipients)
+		}
+	}
+
+	receipts, err := l.next.Send(message, recipients)
+	now := time.Now()
+
+	if err != nil {
+		ids := make([]uint, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ID
+		}
+		if dbErr := initializers.DB.Model(&models.SmsMessage{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status": models.SmsStatusFailed,
+			"error":  err.Error(),
+		}).Error; dbErr != nil {
+			log.Println("SMS log: failed to record send failure:", dbErr)
+		}
+		return receipts, err
+	}
+
+	// Receipts come back in recipient order. A delivery report may already have
+	// moved the message past sent, so only queued entries are updated.
+	for i, entry := range entries {
+		updates := map[string]interface{}{"status": models.SmsStatusSent, "sent_at": now}
+		if i < len(receipts) && receipts[i].Error != "" {
+			updates = map[string]interface{}{"status": models.SmsStatusFailed, "error": receipts[i].Error}
+		} else if i < len(receipts) && receipts[i].MessageID != "" {
+			updates["provider_message_id"] = receipts[i].MessageID
+		}
+		if dbErr := initializers.DB.Model(&models.SmsMessage{}).
+			Where("id = ? AND status = ?", entry.ID, models.SmsStatusQueued).
+			Updates(updates).Error; dbErr != nil {
+			log.Println("SMS log: failed to record sent message", entry.ID, ":", dbErr)
+		}
+	}
+	return receipts, nil
+}
+
+// ErrUnknownSmsStatus is returned for delivery reports with a status we cannot map
+var ErrUnknownSmsStatus = errors.New("unknown delivery status")
+
+// normalizeSmsStatus maps the statuses and DLR codes providers report onto the SMS log statuses
+func normalizeSmsStatus(status string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "sent", "accepted", "enroute", "submitted":
+		return models.SmsStatusSent, nil
+	case "delivered", "delivrd", "success":
+		return models.SmsStatusDelivered, nil
+	case "failed", "undeliv", "undelivered", "rejected", "rejectd", "expired", "deleted":
+		return models.SmsStatusFailed, nil
+	}
+	return "", ErrUnknownSmsStatus
+}
+
+// smsStatusesBefore lists the statuses a report of the given status may replace,
+// so a late "sent" report never overwrites a final delivered or failed one
+func smsStatusesBefore(status string) []string {
+	if status == models.SmsStatusSent {
+		return []string{models.SmsStatusQueued}
+	}
+	return []string{models.SmsStatusQueued, models.SmsStatusSent}
+}
+
+// ApplyDeliveryReport updates the logged message a provider delivery report
+// refers to. It matches on the provider's message ID, or on the recipient's
+// latest sent message when the provider gave no ID. It returns
+// gorm.ErrRecordNotFound if no logged message matches.
+func ApplyDeliveryReport(report helpers.SmsDeliveryReport) (models.SmsMessage, error) {
+	var message models.SmsMessage
+	status, err := normalizeSmsStatus(report.Status)
+	if err != nil {
+		return message, err
+	}
+
+	query := initializers.DB.Order("id DESC")
+	if report.Provider != "" {
+		query = query.Where("provider = ?", report.Provider)
+	}
+	switch {
+	case report.MessageID != "":
+		query = query.Where("provider_message_id = ?", report.MessageID)
+	case report.Recipient != "":
+		recipient := report.Recipient
+		if phone, err := helpers.NormalizePhone(recipient); err == nil {
+			recipient = phone
+		}
+		query = query.Where("recipient = ? AND status = ?", recipient, models.SmsStatusSent)
+	default:
+		return message, gorm.ErrRecordNotFound
+	}
+	if err := query.First(&message).Error; err != nil {
+		return message, err
+	}
+
+	at := time.Now()
+	if report.Timestamp != nil {
+		at = *report.Timestamp
+	}
+	updates := map[string]interface{}{"status": status}
+	switch status {
+	case models.SmsStatusDelivered:
+		updates["delivered_at"] = at
+		updates["error"] = ""
+	case models.SmsStatusFailed:
+		updates["error"] = report.Error
+	}
+	if message.SentAt == nil {
+		updates["sent_at"] = at
+	}
+
+	// Reports can arrive out of order; never step back from a final status
+	if err := initializers.DB.Model(&message).
+		Where("status IN ?", smsStatusesBefore(status)).
+		Updates(updates).Error; err != nil {
+		return message, err
+	}
+	return message, initializers.DB.First(&message, message.ID).Error
+}
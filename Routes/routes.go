@@ -6,6 +6,8 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/group4/campus-connect-api/Controllers"
+	"github.com/group4/campus-connect-api/Middleware"
+	models "github.com/group4/campus-connect-api/Models"
 )
 
 // all web routes defined here
@@ -25,42 +27,104 @@ func Routes() {
 	// Serve static files from the uploads directory (public)
 	r.Static("/Images", "./Images")
 
-	// Public routes (no authentication required)
+	// Read routes are public; write routes require a bearer access token from /api/user/login.
+	// Jobs, events and timetables can additionally only be managed by staff and admins.
 	// User routes
 	r.POST("/api/user/register", controllers.CreateUser)
 	r.POST("/api/user/login", controllers.Login)
-	r.GET("/api/users", controllers.GetUsers)
-	r.GET("/api/users/:id", controllers.GetUserByID)
-	r.PUT("/api/users/:id/update", controllers.UpdateUser)
-	r.DELETE("/api/users/:id/delete", controllers.DeleteUser)
+	r.POST("/api/user/login/2fa", controllers.LoginTwoFactor)
+	r.POST("/api/user/refresh", controllers.RefreshSession)
+	r.POST("/api/user/password/forgot", controllers.ForgotPassword)
+	r.POST("/api/user/password/reset", controllers.ResetPassword)
+	r.POST("/api/user/logout", middleware.RequireAuth, controllers.Logout)
+	r.POST("/api/user/phone/verify", middleware.RequireAuth, controllers.VerifyPhone)
+	r.POST("/api/user/phone/resend", middleware.RequireAuth, controllers.ResendPhoneVerification)
+	r.GET("/api/user/sessions", middleware.RequireAuth, controllers.GetSessions)
+	r.DELETE("/api/user/sessions/:id", middleware.RequireAuth, controllers.RevokeSession)
+	r.POST("/api/user/2fa/setup", middleware.RequireAuth, middleware.RequirePermission(models.PermTwoFactor), controllers.SetupTwoFactor)
+	r.POST("/api/user/2fa/confirm", middleware.RequireAuth, middleware.RequirePermission(models.PermTwoFactor), controllers.ConfirmTwoFactor)
+	r.POST("/api/user/2fa/disable", middleware.RequireAuth, controllers.DisableTwoFactor)
+	r.POST("/api/user/2fa/recovery-codes", middleware.RequireAuth, controllers.RegenerateRecoveryCodes)
+	r.GET("/api/users", middleware.OptionalAuth, controllers.GetUsers)
+	r.GET("/api/users/:id", middleware.OptionalAuth, controllers.GetUserByID)
+	r.PUT("/api/users/:id/update", middleware.RequireAuth, controllers.UpdateUser)
+	r.DELETE("/api/users/:id/delete", middleware.RequireAuth, controllers.DeleteUser)
+	r.POST("/api/users/:id/unlock", middleware.RequireAuth, middleware.RequirePermission(models.PermManageUsers), controllers.UnlockUser)
+	r.POST("/api/users/:id/unsuspend", middleware.RequireAuth, middleware.RequirePermission(models.PermModeratePosts), controllers.UnsuspendUser)
+
+	// Admin routes
+	r.GET("/api/admin/lockouts", middleware.RequireAuth, middleware.RequirePermission(models.PermManageUsers), controllers.GetLockouts)
+	r.GET("/api/admin/outbox", middleware.RequireAuth, middleware.RequirePermission(models.PermManageOutbox), controllers.GetOutboxMessages)
+	r.POST("/api/admin/outbox/:id/retry", middleware.RequireAuth, middleware.RequirePermission(models.PermManageOutbox), controllers.RetryOutboxMessage)
+	r.POST("/api/admin/broadcasts/preview", middleware.RequireAuth, middleware.RequirePermission(models.PermBroadcast), controllers.PreviewBroadcast)
+	r.POST("/api/admin/broadcasts", middleware.RequireAuth, middleware.RequirePermission(models.PermBroadcast), controllers.CreateBroadcast)
+	r.GET("/api/admin/broadcasts", middleware.RequireAuth, middleware.RequirePermission(models.PermBroadcast), controllers.GetBroadcasts)
+	r.GET("/api/admin/broadcasts/:id", middleware.RequireAuth, middleware.RequirePermission(models.PermBroadcast), controllers.GetBroadcastByID)
+	r.GET("/api/admin/sms", middleware.RequireAuth, middleware.RequirePermission(models.PermManageOutbox), controllers.GetSmsMessages)
+
+	// Moderation routes
+	r.GET("/api/moderation/reports", middleware.RequireAuth, middleware.RequirePermission(models.PermModeratePosts), controllers.GetReports)
+	r.POST("/api/moderation/reports/:id/action", middleware.RequireAuth, middleware.RequirePermission(models.PermModeratePosts), controllers.ModerateReport)
+
+	// Provider webhooks, authenticated with a shared secret instead of a token
+	r.POST("/api/webhooks/sms/delivery", controllers.ReceiveSmsDeliveryReport)
 
 	// Post routes
-	r.GET("/api/posts", controllers.GetPosts)
-	r.POST("/api/posts", controllers.CreatePost)
-	r.GET("/api/posts/:id", controllers.GetPostByID)
-	r.PUT("/api/posts/:id/update", controllers.UpdatePost)
-	r.DELETE("/api/posts/:id/delete", controllers.DeletePost)
+	r.GET("/api/posts", middleware.OptionalAuth, controllers.GetPosts)
+	r.GET("/api/posts/drafts", middleware.RequireAuth, controllers.GetMyDrafts)
+	r.POST("/api/posts", middleware.RequireAuth, middleware.RequireVerifiedPhone, controllers.CreatePost)
+	r.GET("/api/posts/:id", middleware.OptionalAuth, controllers.GetPostByID)
+	r.PUT("/api/posts/:id/update", middleware.RequireAuth, controllers.UpdatePost)
+	r.DELETE("/api/posts/:id/delete", middleware.RequireAuth, controllers.DeletePost)
+	r.POST("/api/posts/:id/images", middleware.RequireAuth, controllers.AddPostImage)
+	r.PUT("/api/posts/:id/images/order", middleware.RequireAuth, controllers.ReorderPostImages)
+	r.PUT("/api/posts/:id/images/:imageId/update", middleware.RequireAuth, controllers.UpdatePostImage)
+	r.DELETE("/api/posts/:id/images/:imageId/delete", middleware.RequireAuth, controllers.DeletePostImage)
+	r.GET("/api/posts/:id/comments", middleware.OptionalAuth, controllers.GetComments)
+	r.POST("/api/posts/:id/comments", middleware.RequireAuth, middleware.RequireVerifiedPhone, controllers.CreateComment)
+	r.PUT("/api/posts/:id/comments/:commentId/update", middleware.RequireAuth, controllers.UpdateComment)
+	r.DELETE("/api/posts/:id/comments/:commentId/delete", middleware.RequireAuth, controllers.DeleteComment)
+	r.POST("/api/posts/:id/report", middleware.RequireAuth, controllers.ReportPost)
+	r.POST("/api/posts/:id/comments/:commentId/report", middleware.RequireAuth, controllers.ReportComment)
+	r.POST("/api/posts/:id/reactions", middleware.RequireAuth, middleware.RequireVerifiedPhone, controllers.ToggleReaction)
+	r.DELETE("/api/posts/:id/reactions", middleware.RequireAuth, controllers.RemoveReaction)
+
+	// Feed routes
+	r.GET("/api/feed", middleware.OptionalAuth, controllers.GetFeed)
+
+	// Tag routes
+	r.GET("/api/tags/trending", controllers.GetTrendingTags)
+	r.GET("/api/tags/:tag/posts", middleware.OptionalAuth, controllers.GetTagPosts)
 
 	// Job routes
 	r.GET("/api/jobs", controllers.GetJobs)
-	r.POST("/api/jobs", controllers.CreateJob)
+	r.POST("/api/jobs", middleware.RequireAuth, middleware.RequirePermission(models.PermManageJobs), controllers.CreateJob)
 	r.GET("/api/jobs/:id", controllers.GetJobByID)
-	r.PUT("/api/jobs/:id/update", controllers.UpdateJob)
-	r.DELETE("/api/jobs/:id/delete", controllers.DeleteJob)
+	r.PUT("/api/jobs/:id/update", middleware.RequireAuth, middleware.RequirePermission(models.PermManageJobs), controllers.UpdateJob)
+	r.DELETE("/api/jobs/:id/delete", middleware.RequireAuth, middleware.RequirePermission(models.PermManageJobs), controllers.DeleteJob)
 
 	// Event routes
 	r.GET("/api/events", controllers.GetEvents)
-	r.POST("/api/events", controllers.CreateEvent)
+	r.POST("/api/events", middleware.RequireAuth, middleware.RequirePermission(models.PermManageEvents), controllers.CreateEvent)
 	r.GET("/api/events/:id", controllers.GetEventByID)
-	r.PUT("/api/events/:id/update", controllers.UpdateEvent)
-	r.DELETE("/api/events/:id/delete", controllers.DeleteEvent)
+	r.PUT("/api/events/:id/update", middleware.RequireAuth, middleware.RequirePermission(models.PermManageEvents), controllers.UpdateEvent)
+	r.DELETE("/api/events/:id/delete", middleware.RequireAuth, middleware.RequirePermission(models.PermManageEvents), controllers.DeleteEvent)
+	r.POST("/api/events/:id/follow", middleware.RequireAuth, middleware.RequireVerifiedPhone, controllers.FollowEvent)
+	r.DELETE("/api/events/:id/follow", middleware.RequireAuth, controllers.UnfollowEvent)
 
 	// Timetable routes
 	r.GET("/api/timetables", controllers.GetTimetables)
-	r.POST("/api/timetables", controllers.CreateTimetable)
+	r.POST("/api/timetables", middleware.RequireAuth, middleware.RequirePermission(models.PermManageTimetables), controllers.CreateTimetable)
 	r.GET("/api/timetables/:id", controllers.GetTimetableByID)
-	r.PUT("/api/timetables/:id/update", controllers.UpdateTimetable)
-	r.DELETE("/api/timetables/:id/delete", controllers.DeleteTimetable)
+	r.PUT("/api/timetables/:id/update", middleware.RequireAuth, middleware.RequirePermission(models.PermManageTimetables), controllers.UpdateTimetable)
+	r.DELETE("/api/timetables/:id/delete", middleware.RequireAuth, middleware.RequirePermission(models.PermManageTimetables), controllers.DeleteTimetable)
+
+	// Notification routes
+	r.GET("/api/notifications", middleware.RequireAuth, controllers.GetNotifications)
+	r.GET("/api/notifications/preferences", middleware.RequireAuth, controllers.GetNotificationPreferences)
+	r.PUT("/api/notifications/preferences", middleware.RequireAuth, controllers.UpdateNotificationPreferences)
+	r.PUT("/api/notifications/read-all", middleware.RequireAuth, controllers.MarkAllNotificationsRead)
+	r.PUT("/api/notifications/:id/read", middleware.RequireAuth, controllers.MarkNotificationRead)
 
 	r.Run()
 }
\ No newline at end of file
@@ -3,13 +3,22 @@ package main
 import (
 	initializers "github.com/group4/campus-connect-api/Initializers"
 	migrations "github.com/group4/campus-connect-api/Migrations"
+	notifications "github.com/group4/campus-connect-api/Notifications"
 	routes "github.com/group4/campus-connect-api/Routes"
 )
 
 func init() {
 	initializers.LoadEnvVariables()
+	initializers.ConfigureAuth()
 	initializers.ConnectToDB()
+	initializers.ConfigureSMS()
+	initializers.ConfigureEmail()
 	migrations.SyncDatabase()
+	notifications.EnableSmsLog()
+	notifications.StartOutboxWorker()
+	notifications.StartEventReminders()
+	notifications.StartPostScheduler()
+	notifications.StartBroadcastWorker()
 	routes.Routes()
 }
 